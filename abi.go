@@ -0,0 +1,780 @@
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// abiArg is one named value of an ABI encoded tuple, like a function argument
+type abiArg struct {
+	name string
+	typ  abi.Type
+}
+
+// splitABITypes splits a comma separated list of types, like the inside of a
+// function signature, leaving the commas inside tuples alone
+func splitABITypes(s string) ([]string, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+	var types []string
+	depth, start := 0, 0
+	for i, c := range s {
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth < 0 {
+				return nil, fmt.Errorf("unbalanced parentheses in %q", s)
+			}
+		case ',':
+			if depth == 0 {
+				types = append(types, strings.TrimSpace(s[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	if depth != 0 {
+		return nil, fmt.Errorf("unbalanced parentheses in %q", s)
+	}
+	return append(types, strings.TrimSpace(s[start:])), nil
+}
+
+// unnamedField prefixes the placeholder names of tuple fields parsed from a type string,
+// since the abi package insists on names
+const unnamedField = "unnamedField"
+
+// abiMarshaling turns a type like uint256, bytes[] or (address,uint256)[] into the
+// form the abi package builds types from. Tuple fields get placeholder names.
+func abiMarshaling(s string) (abi.ArgumentMarshaling, error) {
+	if !strings.HasPrefix(s, "(") {
+		// int and uint are aliases that never appear in signatures, but people type them
+		for _, alias := range []string{"uint", "int"} {
+			if s == alias || strings.HasPrefix(s, alias+"[") {
+				s = alias + "256" + s[len(alias):]
+			}
+		}
+		return abi.ArgumentMarshaling{Type: s}, nil
+	}
+
+	end := strings.LastIndex(s, ")")
+	fields, err := splitABITypes(s[1:end])
+	if err != nil {
+		return abi.ArgumentMarshaling{}, err
+	}
+	m := abi.ArgumentMarshaling{Type: "tuple" + s[end+1:]}
+	for i, f := range fields {
+		c, err := abiMarshaling(f)
+		if err != nil {
+			return m, err
+		}
+		c.Name = fmt.Sprintf("%s%d", unnamedField, i)
+		m.Components = append(m.Components, c)
+	}
+	return m, nil
+}
+
+// parseABIType parses a single type like uint256, bytes[] or (address,uint256)[]
+func parseABIType(s string) (abi.Type, error) {
+	m, err := abiMarshaling(strings.TrimSpace(s))
+	if err != nil {
+		return abi.Type{}, err
+	}
+	t, err := abi.NewType(m.Type, "", m.Components)
+	if err != nil {
+		return abi.Type{}, err
+	}
+	return t, checkABIType(t)
+}
+
+// checkABIType catches the sizes the abi package lets through but that no
+// encoder can produce, like int0 or bytes33, in t and any types inside it
+func checkABIType(t abi.Type) error {
+	switch t.T {
+	case abi.IntTy, abi.UintTy:
+		if t.Size < 8 || t.Size > 256 || t.Size%8 != 0 {
+			return fmt.Errorf("%s isn't a valid type, integers are 8 to 256 bits in steps of 8", t.String())
+		}
+	case abi.FixedBytesTy:
+		if t.Size < 1 || t.Size > 32 {
+			return fmt.Errorf("%s isn't a valid type, fixed size bytes are 1 to 32 bytes", t.String())
+		}
+	case abi.ArrayTy, abi.SliceTy:
+		return checkABIType(*t.Elem)
+	case abi.TupleTy:
+		for _, elem := range t.TupleElems {
+			if err := checkABIType(*elem); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// parseABITypes parses a comma separated list of types into unnamed arguments
+func parseABITypes(s string) ([]abiArg, error) {
+	types, err := splitABITypes(s)
+	if err != nil {
+		return nil, err
+	}
+	args := make([]abiArg, len(types))
+	for i, t := range types {
+		if args[i].typ, err = parseABIType(t); err != nil {
+			return nil, err
+		}
+	}
+	return args, nil
+}
+
+// parseArgumentTypes parses the types of a list of values like (uint256,bytes,address[]).
+// The outer parentheses are optional and list the types like a signature does,
+// rather than making them a single tuple.
+func parseArgumentTypes(s string) ([]abiArg, error) {
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(s, "(") && strings.HasSuffix(s, ")") {
+		depth := 0
+		for i, c := range s {
+			if c == '(' {
+				depth++
+			} else if c == ')' {
+				depth--
+			}
+			// the first parenthesis closes before the end, like in (uint256,bool),(bytes)
+			if depth == 0 && i < len(s)-1 {
+				return parseABITypes(s)
+			}
+		}
+		s = s[1 : len(s)-1]
+	}
+	return parseABITypes(s)
+}
+
+// parseSignature splits a signature like transfer(address,uint256) into its
+// name and argument types
+func parseSignature(sig string) (string, []abiArg, error) {
+	open := strings.Index(sig, "(")
+	if open < 1 || !strings.HasSuffix(sig, ")") {
+		return "", nil, fmt.Errorf("%q is not a signature like name(type,type)", sig)
+	}
+	args, err := parseABITypes(sig[open+1 : len(sig)-1])
+	return sig[:open], args, err
+}
+
+// selector is the first 4 bytes of the keccak256 hash of a signature
+func selector(sig string) string {
+	return hex.EncodeToString(crypto.Keccak256([]byte(sig))[:4])
+}
+
+// isDynamic reports whether values of t are encoded in the tail, behind an offset
+func isDynamic(t abi.Type) bool {
+	switch t.T {
+	case abi.StringTy, abi.BytesTy, abi.SliceTy:
+		return true
+	case abi.ArrayTy:
+		return isDynamic(*t.Elem)
+	case abi.TupleTy:
+		for _, e := range t.TupleElems {
+			if isDynamic(*e) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// headSize is the number of bytes a value of t takes up in the head of a tuple
+func headSize(t abi.Type) int {
+	if isDynamic(t) {
+		return 32
+	}
+	switch t.T {
+	case abi.ArrayTy:
+		return t.Size * headSize(*t.Elem)
+	case abi.TupleTy:
+		size := 0
+		for _, e := range t.TupleElems {
+			size += headSize(*e)
+		}
+		return size
+	}
+	return 32
+}
+
+// tupleArgs are the fields of a tuple type as arguments, named after the tuple
+func tupleArgs(t abi.Type, tuple string) []abiArg {
+	args := make([]abiArg, len(t.TupleElems))
+	for i, e := range t.TupleElems {
+		name := t.TupleRawNames[i]
+		if strings.HasPrefix(name, unnamedField) {
+			name = fmt.Sprint(i)
+		}
+		args[i] = abiArg{name: tuple + "." + name, typ: *e}
+	}
+	return args
+}
+
+// abiDecoder explains ABI encoded data word by word. Token offsets are relative
+// to the start of data, and end is the furthest byte any token reached.
+// decoded holds the offsets that dynamic values were already explained at, so
+// offsets that share a target don't explain it over and over again.
+type abiDecoder struct {
+	data    []byte
+	end     int
+	decoded map[int]bool
+}
+
+// word reads the 32 byte word at pos
+func (d *abiDecoder) word(pos int) ([]byte, *parseError) {
+	if pos < 0 || pos+32 > len(d.data) {
+		return nil, newParseError(errTruncated, pos, "Expected a 32 byte word here but the data ends after %d bytes.", len(d.data))
+	}
+	if pos+32 > d.end {
+		d.end = pos + 32
+	}
+	return d.data[pos : pos+32], nil
+}
+
+// int reads the word at pos as a length or offset, which have to be small enough to point into the data
+func (d *abiDecoder) int(pos int, what string) (int, *parseError) {
+	w, perr := d.word(pos)
+	if perr != nil {
+		return 0, perr
+	}
+	n := bytesToInt(w)
+	if !n.IsInt64() || n.Int64() > int64(len(d.data)) {
+		return 0, newParseError(errInvalidInput, pos, "This %s is %s, which is more than the %d bytes of data there are.", what, n.String(), len(d.data))
+	}
+	return int(n.Int64()), nil
+}
+
+// tuple explains a tuple encoded at start: the head with a slot for every value, where
+// dynamic values only have an offset to their contents in the tail after the head.
+// what names the tuple in explanations of offsets.
+func (d *abiDecoder) tuple(args []abiArg, start int, what string) ([]token, *parseError) {
+	var toks []token
+	head := start
+	for i, arg := range args {
+		name := arg.name
+		if name == "" {
+			name = fmt.Sprintf("Argument %d", i)
+		}
+		arg.name = name
+
+		if !isDynamic(arg.typ) {
+			tok, perr := d.value(arg, head)
+			if perr != nil {
+				return toks, perr
+			}
+			toks = append(toks, tok)
+			head += headSize(arg.typ)
+			continue
+		}
+
+		off, perr := d.int(head, "offset")
+		if perr != nil {
+			return toks, perr
+		}
+		w := d.data[head : head+32]
+		target := start + off
+		// offsets count from the start of the tuple, so a small one can still point past the end
+		if target+32 > len(d.data) {
+			return toks, newParseError(errInvalidInput, head, "This offset points at byte %d, but the %d bytes of data end before a value could start there.", target, len(d.data))
+		}
+		// Target is shifted into place along with the token but Value isn't, so it says what it counts from
+		offTok := token{
+			Token:       hex.EncodeToString(w),
+			Title:       "Offset of " + name,
+			Description: fmt.Sprintf("%s is a %s, which can be any size, so its slot in the head only holds where its contents start: %d bytes after the start of %s.", name, arg.typ.String(), off, what),
+			FlavorText:  "Keeping every head slot 32 bytes means the position of each value is known without reading the ones before it.",
+			Value:       fmt.Sprintf("%d (byte %d of the encoded values)", off, target),
+			Offset:      head,
+			Length:      32,
+			Target:      &target,
+		}
+		head += 32
+		if d.decoded[target] {
+			offTok.Description += fmt.Sprintf("\nAn earlier offset points at byte %d too, and the value there is explained with it. Encoders never share values like this, but nothing stops it.", target)
+			toks = append(toks, offTok)
+			continue
+		}
+		if d.decoded == nil {
+			d.decoded = map[int]bool{}
+		}
+		d.decoded[target] = true
+		toks = append(toks, offTok)
+
+		tok, perr := d.value(arg, target)
+		if perr != nil {
+			return toks, perr
+		}
+		toks = append(toks, tok)
+	}
+
+	// the tails come after the heads unless the encoding is unusual
+	sort.SliceStable(toks, func(i, j int) bool { return toks[i].Offset < toks[j].Offset })
+	return toks, nil
+}
+
+// value explains the value of arg encoded at pos
+func (d *abiDecoder) value(arg abiArg, pos int) (token, *parseError) {
+	t := arg.typ
+	title := fmt.Sprintf("%s (%s)", arg.name, t.String())
+
+	switch t.T {
+	case abi.StringTy, abi.BytesTy:
+		n, perr := d.int(pos, "length")
+		if perr != nil {
+			return token{}, perr
+		}
+		padded := (n + 31) / 32 * 32
+		if pos+32+padded > len(d.data) {
+			return token{}, newParseError(errTruncated, pos, "The length says %d bytes follow but the data ends first.", n)
+		}
+		if pos+32+padded > d.end {
+			d.end = pos + 32 + padded
+		}
+		content := d.data[pos+32 : pos+32+n]
+		value := "0x" + hex.EncodeToString(content)
+		if t.T == abi.StringTy {
+			value = fmt.Sprintf("%q", string(content))
+		}
+		if len(value) > 130 {
+			value = value[:128] + "..."
+		}
+		children := []token{lengthToken(d.data[pos:pos+32], n, "bytes", pos)}
+		if padded > 0 {
+			children = append(children, token{
+				Token:       abbreviateHex(d.data[pos+32 : pos+32+padded]),
+				Title:       "Contents",
+				Description: fmt.Sprintf("The %d bytes of the %s, padded with zeros to a multiple of 32 bytes.", n, t.String()),
+				Value:       value,
+				Offset:      pos + 32,
+				Length:      padded,
+			})
+		}
+		return token{
+			Token:       abbreviateHex(d.data[pos : pos+32+padded]),
+			Title:       title,
+			Description: fmt.Sprintf("A %s is encoded as its length in bytes followed by the bytes themselves.", t.String()),
+			Value:       value,
+			Offset:      pos,
+			Length:      32 + padded,
+			Children:    children,
+		}, nil
+
+	case abi.SliceTy, abi.ArrayTy:
+		n, start := t.Size, pos
+		var children []token
+		if t.T == abi.SliceTy {
+			var perr *parseError
+			if n, perr = d.int(pos, "length"); perr != nil {
+				return token{}, perr
+			}
+			// every item takes at least its head slot after the length
+			if pos+32+n*headSize(*t.Elem) > len(d.data) {
+				return token{}, newParseError(errInvalidInput, pos, "The array claims %d items, more than could fit in the data after it.", n)
+			}
+			children = append(children, lengthToken(d.data[pos:pos+32], n, "items", pos))
+			start += 32
+		}
+		elems := make([]abiArg, n)
+		for i := range elems {
+			elems[i] = abiArg{name: fmt.Sprintf("%s[%d]", arg.name, i), typ: *t.Elem}
+		}
+		items, perr := d.tuple(elems, start, arg.name)
+		if perr != nil {
+			return token{}, perr
+		}
+		children = append(children, items...)
+		desc := fmt.Sprintf("A fixed size array of %d items, encoded like a tuple of them.", n)
+		if t.T == abi.SliceTy {
+			desc = "A dynamic array is encoded as the number of items followed by the items, which are encoded like a tuple of them. Offsets inside it count from the first item."
+		}
+		return spanToken(d.data, pos, title, desc, fmt.Sprintf("%d items", n), children), nil
+
+	case abi.TupleTy:
+		children, perr := d.tuple(tupleArgs(t, arg.name), pos, arg.name)
+		if perr != nil {
+			return token{}, perr
+		}
+		desc := "A struct, encoded as a tuple of its fields."
+		if isDynamic(t) {
+			desc += " Offsets inside it count from its start."
+		}
+		return spanToken(d.data, pos, title, desc, fmt.Sprintf("%d fields", len(t.TupleElems)), children), nil
+	}
+
+	w, perr := d.word(pos)
+	if perr != nil {
+		return token{}, perr
+	}
+	value, desc, err := describeABIWord(t, w)
+	if err != nil {
+		return token{}, newParseError(errInvalidInput, pos, "%s isn't a valid %s: %v", arg.name, t.String(), err)
+	}
+	return token{
+		Token:       hex.EncodeToString(w),
+		Title:       title,
+		Description: desc,
+		Value:       value,
+		Offset:      pos,
+		Length:      32,
+	}, nil
+}
+
+// unusedTokens explains the bytes after the furthest one any token reached, if there
+// are any. what names the values that were decoded.
+func (d *abiDecoder) unusedTokens(what string) []token {
+	if d.end >= len(d.data) {
+		return nil
+	}
+	return []token{{
+		Token:       abbreviateHex(d.data[d.end:]),
+		Title:       "Unused Bytes",
+		Description: fmt.Sprintf("Bytes after %s. Decoding ignores them, but something put them there.", what),
+		Value:       "0x" + abbreviateHex(d.data[d.end:]),
+		Offset:      d.end,
+		Length:      len(d.data) - d.end,
+	}}
+}
+
+// spanToken groups children, which start at pos, into a token reaching to the end of the last of them
+func spanToken(data []byte, pos int, title, desc, value string, children []token) token {
+	end := pos
+	for _, c := range children {
+		if c.Offset+c.Length > end {
+			end = c.Offset + c.Length
+		}
+	}
+	return token{
+		Token:       abbreviateHex(data[pos:end]),
+		Title:       title,
+		Description: desc,
+		Value:       value,
+		Offset:      pos,
+		Length:      end - pos,
+		Children:    children,
+	}
+}
+
+func lengthToken(w []byte, n int, unit string, pos int) token {
+	return token{
+		Token:       hex.EncodeToString(w),
+		Title:       "Length",
+		Description: fmt.Sprintf("The number of %s that follow.", unit),
+		Value:       fmt.Sprintf("%d %s", n, unit),
+		Offset:      pos,
+		Length:      32,
+	}
+}
+
+// describeABIWord decodes a value that fits in one word, checking its padding
+func describeABIWord(t abi.Type, w []byte) (value, desc string, err error) {
+	// types are checked when they are parsed, but a bad size here would panic
+	if err := checkABIType(t); err != nil {
+		return "", "", err
+	}
+	n := bytesToInt(w)
+	switch t.T {
+	case abi.UintTy:
+		if n.BitLen() > t.Size {
+			return "", "", fmt.Errorf("the value needs more than %d bits", t.Size)
+		}
+		return n.String(), fmt.Sprintf("An unsigned integer of %d bits, padded on the left to 32 bytes.", t.Size), nil
+	case abi.IntTy:
+		// two's complement
+		if w[0]&0x80 != 0 {
+			n.Sub(n, new(big.Int).Lsh(big.NewInt(1), 256))
+		}
+		min := new(big.Int).Neg(new(big.Int).Lsh(big.NewInt(1), uint(t.Size-1)))
+		if n.Cmp(min) < 0 || n.Cmp(new(big.Int).Neg(min)) >= 0 {
+			return "", "", fmt.Errorf("the value doesn't fit in %d bits", t.Size)
+		}
+		return n.String(), fmt.Sprintf("A signed integer of %d bits in two's complement, padded on the left to 32 bytes (with 0xff bytes if it is negative).", t.Size), nil
+	case abi.AddressTy:
+		if !isZero(w[:12]) {
+			return "", "", fmt.Errorf("the first 12 bytes should be zero padding")
+		}
+		return common.BytesToAddress(w).Hex(), "A 20 byte address, padded on the left with 12 zero bytes.", nil
+	case abi.BoolTy:
+		if n.Cmp(big.NewInt(1)) > 0 {
+			return "", "", fmt.Errorf("a bool can only be 0 or 1")
+		}
+		return fmt.Sprintf("%t", n.Sign() == 1), "A bool, 0 for false or 1 for true, padded on the left to 32 bytes.", nil
+	case abi.FixedBytesTy, abi.FunctionTy:
+		if !isZero(w[t.Size:]) {
+			return "", "", fmt.Errorf("only the first %d bytes can be set, the rest is zero padding", t.Size)
+		}
+		desc := fmt.Sprintf("%d bytes, padded on the right with zeros to 32 bytes.", t.Size)
+		if t.T == abi.FunctionTy {
+			desc = "A function pointer: a 20 byte address followed by a 4 byte function selector, padded on the right to 32 bytes."
+		}
+		return "0x" + hex.EncodeToString(w[:t.Size]), desc, nil
+	}
+	return "", "", fmt.Errorf("can't decode %s values", t.String())
+}
+
+func isZero(b []byte) bool {
+	for _, c := range b {
+		if c != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// wordTokens splits data into 32 byte words when we don't know its types.
+// Offsets are relative to the start of data.
+func wordTokens(data []byte) []token {
+	var toks []token
+	for pos := 0; pos < len(data); pos += 32 {
+		if pos+32 > len(data) {
+			toks = append(toks, token{
+				Token:       hex.EncodeToString(data[pos:]),
+				Title:       "Trailing Bytes",
+				Description: fmt.Sprintf("%d bytes that don't make up a whole 32 byte word. ABI encoded data is always a multiple of 32 bytes.", len(data)-pos),
+				Value:       "0x" + hex.EncodeToString(data[pos:]),
+				Offset:      pos,
+				Length:      len(data) - pos,
+			})
+			break
+		}
+		w := data[pos : pos+32]
+		toks = append(toks, token{
+			Token:       hex.EncodeToString(w),
+			Title:       fmt.Sprintf("Word %d", pos/32),
+			Description: "A 32 byte word. Without knowing the types it could be a number, an address, an offset to a dynamic value or part of one.",
+			FlavorText:  guessWord(w),
+			Value:       "0x" + hex.EncodeToString(w),
+			Offset:      pos,
+			Length:      32,
+		})
+	}
+	return toks
+}
+
+// guess at what an unknown word holds
+func guessWord(w []byte) string {
+	n := bytesToInt(w)
+	switch {
+	case n.Sign() == 0:
+		return "All zeros: 0, false, the zero address or an empty value."
+	case isZero(w[:12]) && !isZero(w[12:16]):
+		return fmt.Sprintf("Looks like an address padded on the left: %s.", common.BytesToAddress(w).Hex())
+	case n.BitLen() <= 64:
+		return fmt.Sprintf("As a number this is %s. Small multiples of 32 are often offsets or lengths.", n.String())
+	case isZero(w[len(w)-8:]):
+		return "Zeros on the right suggest fixed size bytes or text padded on the right."
+	}
+	return fmt.Sprintf("As a number this is %s.", n.String())
+}
+
+// unwrapABI finds the ABI's JSON array. Besides the plain array it takes the ABI as a
+// JSON string, like Etherscan's API returns it, or a build artifact with an abi field.
+func unwrapABI(raw []byte) []byte {
+	var inner string
+	if err := json.Unmarshal(raw, &inner); err == nil {
+		raw = []byte(inner)
+	}
+	var artifact struct {
+		ABI json.RawMessage `json:"abi"`
+	}
+	if err := json.Unmarshal(raw, &artifact); err == nil && len(artifact.ABI) > 0 {
+		raw = artifact.ABI
+	}
+	return raw
+}
+
+// parseContractABI parses a contract ABI in any of the forms unwrapABI takes
+func parseContractABI(raw []byte) (*abi.ABI, error) {
+	contract, err := abi.JSON(bytes.NewReader(unwrapABI(raw)))
+	if err != nil {
+		return nil, err
+	}
+	if err := checkArguments("the constructor", contract.Constructor.Inputs); err != nil {
+		return nil, err
+	}
+	// in order, so the same ABI always fails on the same entry
+	names := make([]string, 0, len(contract.Methods))
+	for name := range contract.Methods {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		m := contract.Methods[name]
+		if err := checkArguments("function "+name, m.Inputs); err != nil {
+			return nil, err
+		}
+		if err := checkArguments("function "+name, m.Outputs); err != nil {
+			return nil, err
+		}
+	}
+	names = names[:0]
+	for name := range contract.Events {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := checkArguments("event "+name, contract.Events[name].Inputs); err != nil {
+			return nil, err
+		}
+	}
+	return &contract, nil
+}
+
+// checkArguments checks the types of the arguments of an ABI entry like checkABIType does
+func checkArguments(entry string, args abi.Arguments) error {
+	for _, arg := range args {
+		if err := checkABIType(arg.Type); err != nil {
+			return fmt.Errorf("%s: %v", entry, err)
+		}
+	}
+	return nil
+}
+
+// parseContractErrors reads the custom errors of a contract ABI, which the abi package skips
+func parseContractErrors(raw []byte) ([]callCandidate, error) {
+	var entries []struct {
+		Type   string
+		Name   string
+		Inputs []abi.Argument
+	}
+	if err := json.Unmarshal(unwrapABI(raw), &entries); err != nil {
+		return nil, err
+	}
+	var errs []callCandidate
+	for _, e := range entries {
+		if e.Type != "error" {
+			continue
+		}
+		if err := checkArguments("error "+e.Name, e.Inputs); err != nil {
+			return nil, err
+		}
+		c := callCandidate{args: make([]abiArg, len(e.Inputs)), source: "the contract ABI"}
+		types := make([]string, len(e.Inputs))
+		for i, in := range e.Inputs {
+			c.args[i] = abiArg{name: in.Name, typ: in.Type}
+			types[i] = in.Type.String()
+		}
+		c.sig = fmt.Sprintf("%s(%s)", e.Name, strings.Join(types, ","))
+		errs = append(errs, c)
+	}
+	return errs, nil
+}
+
+// callCandidate is a function or error that data starting with its selector might be encoding
+type callCandidate struct {
+	sig  string
+	args []abiArg
+	// where we know the signature from
+	source string
+}
+
+// callCandidates are the functions with the selector sel, from the contract ABI
+// first if there is one and then from our signature database
+func callCandidates(sel []byte, contract *abi.ABI) []callCandidate {
+	var candidates []callCandidate
+	if contract != nil {
+		if method, err := contract.MethodById(sel); err == nil {
+			args := make([]abiArg, len(method.Inputs))
+			for i, in := range method.Inputs {
+				args[i] = abiArg{name: in.Name, typ: in.Type}
+			}
+			candidates = append(candidates, callCandidate{sig: method.Sig(), args: args, source: "the contract ABI"})
+		}
+	}
+	return appendKnownSignatures(candidates, sel, functionSignatures)
+}
+
+// appendKnownSignatures adds the signatures in db with the selector sel to candidates,
+// skipping ones that are already there
+func appendKnownSignatures(candidates []callCandidate, sel []byte, db map[string][]string) []callCandidate {
+	have := map[string]bool{}
+	for _, c := range candidates {
+		have[c.sig] = true
+	}
+	for _, sig := range db[hex.EncodeToString(sel)] {
+		if have[sig] {
+			continue
+		}
+		if _, args, err := parseSignature(sig); err == nil {
+			candidates = append(candidates, callCandidate{sig: sig, args: args, source: "our signature database"})
+		}
+	}
+	return candidates
+}
+
+// selectorTokens explains data that starts with a 4 byte selector, the way calls and
+// errors are encoded. It decodes the rest as the arguments of the first candidate they
+// fit, or as 32 byte words if there is none, and fills in the value and flavor text of
+// selTok. ok reports whether a candidate fit. Offsets are relative to the start of data.
+func selectorTokens(data []byte, selTok token, candidates []callCandidate) (c callCandidate, toks []token, ok bool) {
+	sel := hex.EncodeToString(data[:4])
+	args := data[4:]
+	selTok.Token = sel
+	selTok.Value = "0x" + sel
+	selTok.Length = 4
+
+	var failures []string
+	for _, c := range candidates {
+		d := &abiDecoder{data: args}
+		toks, perr := d.tuple(c.args, 0, "the arguments")
+		if perr != nil {
+			failures = append(failures, fmt.Sprintf("%s: %s", c.sig, perr.Error()))
+			continue
+		}
+
+		selTok.Value = c.sig
+		selTok.FlavorText = fmt.Sprintf("0x%s is the selector of %s in %s.", sel, c.sig, c.source)
+		if len(candidates) > 1 {
+			sigs := make([]string, len(candidates))
+			for i, other := range candidates {
+				sigs[i] = other.sig
+			}
+			selTok.FlavorText += fmt.Sprintf(" %d signatures share this selector (%s), this is the first one whose arguments fit the data.", len(candidates), strings.Join(sigs, ", "))
+		}
+		toks = append(toks, d.unusedTokens(fmt.Sprintf("the arguments of %s", c.sig))...)
+		return c, append([]token{selTok}, shiftTokens(toks, 4)...), true
+	}
+
+	selTok.FlavorText = fmt.Sprintf("0x%s isn't a selector we know, so the arguments can only be shown as 32 byte words.", sel)
+	if len(failures) > 0 {
+		selTok.FlavorText = fmt.Sprintf("The arguments don't decode as any signature with this selector. %s", strings.Join(failures, "; "))
+	}
+	return callCandidate{}, append([]token{selTok}, shiftTokens(wordTokens(args), 4)...), false
+}
+
+// calldataTokens explains the data of a call: the 4 byte selector that picks the function,
+// followed by its ABI encoded arguments. The function is looked up in contract if it
+// isn't nil, then in our signature database. It returns a short summary and the tokens,
+// with offsets relative to the start of data.
+func calldataTokens(data []byte, contract *abi.ABI) (string, []token) {
+	if len(data) < 4 {
+		return "0x" + hex.EncodeToString(data), []token{{
+			Token:       hex.EncodeToString(data),
+			Title:       "Raw Data",
+			Description: "Too short to be a call to a contract function, which starts with a 4 byte selector.",
+			Value:       "0x" + hex.EncodeToString(data),
+			Length:      len(data),
+		}}
+	}
+
+	c, toks, ok := selectorTokens(data, token{
+		Title:       "Function Selector",
+		Description: "The first 4 bytes of the keccak256 hash of the function's signature, like transfer(address,uint256). The contract uses it to pick which function to run.",
+	}, callCandidates(data[:4], contract))
+	if !ok {
+		return fmt.Sprintf("0x%x (unknown function)", data[:4]), toks
+	}
+	return c.sig, toks
+}
@@ -0,0 +1,206 @@
+package main
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// abiDataParser explains ABI encoded values without a function selector in front,
+// like return data, event data or constructor arguments.
+type abiDataParser struct{}
+
+func init() {
+	registerParser(parserInfo{
+		Name:        "abi",
+		Type:        "ABI Encoded Data",
+		Priority:    60,
+		Description: "ABI encoded values such as return data, event data or constructor arguments, decoded with the types from the request or by guessing at the layout.",
+	}, &abiDataParser{})
+}
+
+func (a *abiDataParser) understands(s string) bool {
+	buf, err := hex.DecodeString(strings.TrimPrefix(s, "0x"))
+	// a single word is left to the word parser
+	return err == nil && len(buf) >= 64 && len(buf)%32 == 0
+}
+
+func (a *abiDataParser) confidence(s string) float64 {
+	// almost anything can be read as a row of words
+	return 0.35
+}
+
+func (a *abiDataParser) parse(s string, opts options) ([]token, error) {
+	buf, err := decodeHex(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(buf) == 0 {
+		return nil, newParseError(errTruncated, 0, "no data to decode")
+	}
+
+	root := token{
+		Token:       abbreviateHex(buf),
+		Title:       "ABI Encoded Data",
+		Description: "Values encoded with the contract ABI. Each value gets a 32 byte slot in the head, in order. Values that can be any size, like bytes, strings and dynamic arrays, are stored in the tail after the head and their slot holds an offset pointing at them.",
+		Length:      len(buf),
+	}
+
+	if opts.types == nil {
+		root.Value = fmt.Sprintf("%d words, layout guessed", (len(buf)+31)/32)
+		root.FlavorText = "Without the types the layout can only be guessed from the values. Give the types, like (uint256,bytes,address[]), to decode it properly."
+		root.Children = guessABITokens(buf)
+		return []token{root}, nil
+	}
+
+	types := make([]string, len(opts.types))
+	for i, t := range opts.types {
+		types[i] = t.typ.String()
+	}
+	root.Value = "(" + strings.Join(types, ",") + ")"
+
+	d := &abiDecoder{data: buf}
+	toks, perr := d.tuple(opts.types, 0, "the data")
+	if perr != nil {
+		// an error can be at the end of the data, or past it where a value was expected
+		at := perr.Offset
+		if at < 0 || at > len(buf) {
+			at = len(buf)
+		}
+		root.Children = append(toks, errorToken(buf[at:], perr))
+		return []token{root}, perr
+	}
+	root.Children = append(toks, d.unusedTokens("the values")...)
+	return []token{root}, nil
+}
+
+// guessABITokens explains ABI encoded data without knowing its types. A word in the head
+// that points further into the data is taken as an offset, and the length at its target
+// as the start of a bytes value or an array of words, whichever fits the space up to the
+// next target. Everything else in the head is a static value. Offsets are relative to data.
+func guessABITokens(data []byte) []token {
+	var toks []token
+
+	// the first offset points just past the head, which is where the head ends
+	headEnd := len(data)
+	var targets []int
+	for pos := 0; pos+32 <= headEnd; pos += 32 {
+		w := data[pos : pos+32]
+		n := bytesToInt(w)
+		last := -1
+		if len(targets) > 0 {
+			last = targets[len(targets)-1]
+		}
+		// offsets point forward and past each other, to the start of a word
+		if n.IsInt64() && n.Int64()%32 == 0 && n.Int64() > int64(pos) && n.Int64() > int64(last) && n.Int64()+32 <= int64(len(data)) {
+			target := int(n.Int64())
+			if len(targets) == 0 {
+				headEnd = target
+			}
+			targets = append(targets, target)
+			toks = append(toks, token{
+				Token:       hex.EncodeToString(w),
+				Title:       "Offset",
+				Description: fmt.Sprintf("Probably an offset: it points at byte %d, further on at the start of a word. Values that can be any size keep only an offset in the head and their contents in the tail.", target),
+				Value:       fmt.Sprintf("%d (byte %d)", target, target),
+				Offset:      pos,
+				Length:      32,
+				Target:      &target,
+			})
+			continue
+		}
+		toks = append(toks, token{
+			Token:       hex.EncodeToString(w),
+			Title:       "Static Value",
+			Description: "A value that fits in one word, stored right in the head: a number, an address, a bool or a few fixed bytes.",
+			FlavorText:  guessWord(w),
+			Value:       "0x" + hex.EncodeToString(w),
+			Offset:      pos,
+			Length:      32,
+		})
+	}
+
+	for i, target := range targets {
+		end := len(data)
+		if i+1 < len(targets) {
+			end = targets[i+1]
+		}
+		toks = append(toks, guessDynamicTokens(data[target:end], target)...)
+	}
+	return toks
+}
+
+// guessDynamicTokens explains the contents of a dynamic value that starts at pos and
+// runs to the end of region: the length followed by the bytes or items.
+func guessDynamicTokens(region []byte, pos int) []token {
+	tok := token{
+		Token:       hex.EncodeToString(region),
+		Title:       "Dynamic Data",
+		Description: "An offset points here but the first word is too big to be a length. It could be a nested tuple or array, which needs the types to decode.",
+		Value:       fmt.Sprintf("%d bytes", len(region)),
+		Length:      len(region),
+		Children:    wordTokens(region),
+	}
+
+	n := bytesToInt(region[:32])
+	body := region[32:]
+	if !n.IsInt64() || n.Int64() > int64(len(body)) {
+		return shiftTokens([]token{tok}, pos)
+	}
+	length := int(n.Int64())
+	padded := (length + 31) / 32 * 32
+	lengthTok := token{
+		Token:  hex.EncodeToString(region[:32]),
+		Title:  "Length",
+		Length: 32,
+	}
+
+	// a single word could be either, but bytes are padded on the right
+	isArray := length > 0 && length*32 == len(body) && (length != 1 || !isZero(body[1:32]))
+	switch {
+	case isArray:
+		lengthTok.Description = "The number of items in the array that follows."
+		lengthTok.Value = fmt.Sprintf("%d items", length)
+		children := []token{lengthTok}
+		for i := 0; i < length; i++ {
+			w := body[i*32 : i*32+32]
+			children = append(children, token{
+				Token:       hex.EncodeToString(w),
+				Title:       fmt.Sprintf("Item %d", i),
+				Description: "An item of an array of values that each fit in one word.",
+				FlavorText:  guessWord(w),
+				Value:       "0x" + hex.EncodeToString(w),
+				Offset:      32 + i*32,
+				Length:      32,
+			})
+		}
+		tok = spanToken(region, 0, "Dynamic Array", "Probably an array: a length followed by exactly that many words.", fmt.Sprintf("%d items", length), children)
+
+	case padded == len(body):
+		lengthTok.Description = "The number of bytes that follow."
+		lengthTok.Value = fmt.Sprintf("%d bytes", length)
+		content := body[:length]
+		value := "0x" + hex.EncodeToString(content)
+		if length > 0 && isPrintable(content) {
+			value = fmt.Sprintf("%q", string(content))
+		}
+		tok.Title = "Bytes Or String"
+		tok.Description = "Probably bytes or a string: a length followed by that many bytes, padded to a whole word."
+		tok.Value = value
+		tok.Children = []token{lengthTok}
+		if padded > 0 {
+			tok.Children = append(tok.Children, token{
+				Token:       hex.EncodeToString(body),
+				Title:       "Padded Data",
+				Description: fmt.Sprintf("%d bytes of data padded on the right with zeros to a multiple of 32 bytes.", length),
+				Value:       value,
+				Offset:      32,
+				Length:      padded,
+			})
+		}
+
+	default:
+		tok.Description = fmt.Sprintf("An offset points here and the first word could be a length of %d, but what follows isn't that many bytes or words. It could be a nested tuple or array, which needs the types to decode.", length)
+	}
+	return shiftTokens([]token{tok}, pos)
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+)
+
+// an int0 in a contract ABI used to reach describeABIWord and panic
+func TestInvalidIntSizeInABI(t *testing.T) {
+	log, _ := json.Marshal(eventLog{
+		Address: "0x00000000000000000000000000000000000000ff",
+		Topics: []string{
+			"0x" + strings.Repeat("11", 32),
+			"0x" + strings.Repeat("00", 32),
+		},
+		Data: "0x",
+	})
+	tests := []struct {
+		name  string
+		abi   string
+		input string
+		hint  string
+	}{
+		{
+			name:  "calldata",
+			abi:   `[{"type":"function","name":"f","inputs":[{"name":"x","type":"int0"}]}]`,
+			input: "0x12345678" + strings.Repeat("00", 32),
+			hint:  "calldata",
+		},
+		{
+			name:  "event",
+			abi:   `[{"type":"event","name":"E","inputs":[{"name":"x","type":"int0","indexed":true}]}]`,
+			input: string(log),
+			hint:  "log",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, hint := range []string{tt.hint, ""} {
+				_, perr := explain(request{Input: tt.input, Hint: hint, ABI: json.RawMessage(tt.abi)})
+				if perr == nil || perr.Code != errBadRequest {
+					t.Fatalf("hint %q: got error %v, want a %s error", hint, perr, errBadRequest)
+				}
+				if !strings.Contains(perr.Message, "int0") {
+					t.Errorf("hint %q: error %q doesn't name the bad type", hint, perr.Message)
+				}
+			}
+		})
+	}
+}
+
+func TestDescribeABIWordInvalidSize(t *testing.T) {
+	for _, s := range []string{"int0", "uint0", "bytes33"} {
+		typ, err := abi.NewType(s, "", nil)
+		if err != nil {
+			t.Fatalf("%s: %v", s, err)
+		}
+		if _, _, err := describeABIWord(typ, make([]byte, 32)); err == nil {
+			t.Errorf("%s: decoded a word of an impossible type", s)
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"encoding/hex"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// EIP-2930 intrinsic gas, charged up front for every access list entry
+const (
+	accessListAddressGas    = 2400
+	accessListStorageKeyGas = 1900
+)
+
+// checkAccessList makes sure enc is an RLP list of [address, [storageKey, ...]] entries.
+// off is the offset of enc in the input.
+func checkAccessList(enc []byte, off int) *parseError {
+	if err := validRLP(enc, off); err != nil {
+		return toParseError(err, errInvalidRLP)
+	}
+
+	_, content, _, _ := rlp.Split(enc)
+	entryOff := off + len(enc) - len(content)
+	entries, _ := splitRLPItems(content, entryOff)
+	for i, entry := range entries {
+		k, entryContent, _, _ := rlp.Split(entry)
+		if k != rlp.List {
+			return newParseError(errInvalidTx, entryOff, "Access list entry %d should be a list of an address and its storage keys but it is an RLP 'string'.", i)
+		}
+		itemOff := entryOff + len(entry) - len(entryContent)
+		items, _ := splitRLPItems(entryContent, itemOff)
+		if len(items) != 2 {
+			return newParseError(errInvalidTx, entryOff, "Access list entry %d should have 2 items, an address and its storage keys, but it has %d.", i, len(items))
+		}
+
+		k, addr, _, _ := rlp.Split(items[0])
+		if k == rlp.List || len(addr) != 20 {
+			return newParseError(errInvalidTx, itemOff, "Access list entry %d should start with a 20 byte address.", i)
+		}
+
+		keysOff := itemOff + len(items[0])
+		k, keysContent, _, _ := rlp.Split(items[1])
+		if k != rlp.List {
+			return newParseError(errInvalidTx, keysOff, "The storage keys of access list entry %d should be an RLP 'list'.", i)
+		}
+		keyOff := keysOff + len(items[1]) - len(keysContent)
+		keys, _ := splitRLPItems(keysContent, keyOff)
+		for _, key := range keys {
+			if k, c, _, _ := rlp.Split(key); k == rlp.List || len(c) != 32 {
+				return newParseError(errInvalidTx, keyOff, "Storage keys in access list entry %d should be 32 bytes.", i)
+			}
+			keyOff += len(key)
+		}
+
+		entryOff += len(entry)
+	}
+	return nil
+}
+
+// accessListTokens explains each entry of an access list that passed checkAccessList,
+// and counts the addresses and storage keys it declares.
+// Offsets are relative to the start of enc.
+func accessListTokens(enc []byte) (toks []token, addresses, keys int) {
+	_, content, _, _ := rlp.Split(enc)
+	off := len(enc) - len(content)
+	entries, _ := splitRLPItems(content, off)
+	for _, entry := range entries {
+		tok, n := accessListEntryToken(entry)
+		toks = append(toks, shiftTokens([]token{tok}, off)...)
+		addresses++
+		keys += n
+		off += len(entry)
+	}
+	return toks, addresses, keys
+}
+
+// accessListEntryToken explains one [address, [storageKey, ...]] entry.
+// Offsets are relative to the start of entry.
+func accessListEntryToken(entry []byte) (token, int) {
+	_, content, _, _ := rlp.Split(entry)
+	prefix, off := addRLPToken(entry)
+	items, _ := splitRLPItems(content, off)
+	children := []token{*prefix}
+
+	// the address and its length prefix
+	_, addr, _, _ := rlp.Split(items[0])
+	addrPrefix, addrPrefixLen := addRLPToken(items[0])
+	children = append(children, shiftTokens([]token{*addrPrefix, {
+		Token:       hex.EncodeToString(addr),
+		Title:       "Access List Address",
+		Description: "An account the transaction declares it will touch, either by calling it or by reading its balance, code or storage.",
+		FlavorText:  fmt.Sprintf("Declaring it costs %d gas up front. In exchange the first access to it costs 100 gas instead of the 2600 it would cost cold.", accessListAddressGas),
+		Value:       "0x" + hex.EncodeToString(addr),
+		Offset:      addrPrefixLen,
+		Length:      len(addr),
+	}}, off)...)
+	off += len(items[0])
+
+	// the list of storage keys, each with its own prefix
+	_, keysContent, _, _ := rlp.Split(items[1])
+	keysPrefix, keysPrefixLen := addRLPToken(items[1])
+	keyToks := []token{*keysPrefix}
+	keys, _ := splitRLPItems(keysContent, keysPrefixLen)
+	keyOff := keysPrefixLen
+	for _, key := range keys {
+		_, slot, _, _ := rlp.Split(key)
+		keyPrefix, keyPrefixLen := addRLPToken(key)
+		keyToks = append(keyToks, shiftTokens([]token{*keyPrefix, {
+			Token:       hex.EncodeToString(slot),
+			Title:       "Storage Key",
+			Description: fmt.Sprintf("A storage slot of 0x%x that the transaction declares it will read or write.", addr),
+			FlavorText:  describeStorageKey(slot),
+			Value:       "0x" + hex.EncodeToString(slot),
+			Offset:      keyPrefixLen,
+			Length:      len(slot),
+		}}, keyOff)...)
+		keyOff += len(key)
+	}
+	children = append(children, shiftTokens([]token{{
+		Token:       hex.EncodeToString(items[1]),
+		Title:       "Storage Keys",
+		Description: "The storage slots of this address that the transaction will access. Each one is a 32 byte key.",
+		FlavorText:  fmt.Sprintf("Each key costs %d gas up front. In exchange the first read of it costs 100 gas instead of the 2100 it would cost cold.", accessListStorageKeyGas),
+		Value:       fmt.Sprintf("%d keys", len(keys)),
+		Length:      len(items[1]),
+		Children:    keyToks,
+	}}, off)...)
+
+	gas := accessListAddressGas + len(keys)*accessListStorageKeyGas
+	return token{
+		Token:       hex.EncodeToString(entry),
+		Title:       "Access List Entry",
+		Description: "An RLP 'list' of an address followed by an RLP 'list' of the storage keys of that address.",
+		FlavorText:  fmt.Sprintf("This entry adds %d gas: %d for the address and %d x %d for the storage keys.", gas, accessListAddressGas, len(keys), accessListStorageKeyGas),
+		Value:       fmt.Sprintf("0x%x, %d storage keys (+%d gas)", addr, len(keys), gas),
+		Length:      len(entry),
+		Children:    children,
+	}, len(keys)
+}
+
+// describeStorageKey guesses where a storage slot came from
+func describeStorageKey(slot []byte) string {
+	n := bytesToInt(slot)
+	if n.BitLen() <= 64 {
+		return fmt.Sprintf("This is slot %s, which usually holds one of the contract's state variables in the order they were declared.", n.String())
+	}
+	return "Slots that look random are usually keccak256 hashes, which is where Solidity keeps mapping entries and dynamic arrays."
+}
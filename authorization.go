@@ -0,0 +1,133 @@
+package main
+
+import (
+	"encoding/hex"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// EIP-7702 constants
+const (
+	authorizationMagic = 0x05
+	authorizationGas   = 25000
+)
+
+// the fields of an authorization tuple, in order
+var authorizationLayout = []EthField{AUTH_CHAIN_ID, AUTH_ADDRESS, AUTH_NONCE, Y_PARITY, SIG_R, SIG_S}
+
+// checkAuthorizationList makes sure enc is an RLP list of authorization tuples that
+// genToken can explain field by field. off is the offset of enc in the input.
+func checkAuthorizationList(enc []byte, off int) *parseError {
+	if err := validRLP(enc, off); err != nil {
+		return toParseError(err, errInvalidRLP)
+	}
+
+	_, content, _, _ := rlp.Split(enc)
+	tupleOff := off + len(enc) - len(content)
+	tuples, _ := splitRLPItems(content, tupleOff)
+	if len(tuples) == 0 {
+		return newParseError(errInvalidTx, off, "A set code transaction has to carry at least one authorization.")
+	}
+	for i, tuple := range tuples {
+		k, tupleContent, _, _ := rlp.Split(tuple)
+		if k != rlp.List {
+			return newParseError(errInvalidTx, tupleOff, "Authorization %d should be an RLP 'list' but it is an RLP 'string'.", i)
+		}
+		itemOff := tupleOff + len(tuple) - len(tupleContent)
+		items, _ := splitRLPItems(tupleContent, itemOff)
+		if len(items) != len(authorizationLayout) {
+			return newParseError(errInvalidTx, tupleOff, "Authorization %d should have %d items (chain ID, address, nonce, y parity, r and s) but it has %d.", i, len(authorizationLayout), len(items))
+		}
+		for j, item := range items {
+			if perr := checkField(item, authorizationLayout[j], itemOff); perr != nil {
+				return perr
+			}
+			itemOff += len(item)
+		}
+		tupleOff += len(tuple)
+	}
+	return nil
+}
+
+// authorizationTokens explains each tuple of a list that passed checkAuthorizationList.
+// Offsets are relative to the start of enc.
+func authorizationTokens(enc []byte, opts options) []token {
+	_, content, _, _ := rlp.Split(enc)
+	off := len(enc) - len(content)
+	tuples, _ := splitRLPItems(content, off)
+
+	var toks []token
+	for _, tuple := range tuples {
+		toks = append(toks, shiftTokens([]token{authorizationToken(tuple, opts)}, off)...)
+		off += len(tuple)
+	}
+	return toks
+}
+
+// authorizationToken explains one (chainId, address, nonce, yParity, r, s) tuple and
+// who signed it. Offsets are relative to the start of tuple.
+func authorizationToken(tuple []byte, opts options) token {
+	_, content, _, _ := rlp.Split(tuple)
+	prefix, off := addRLPToken(tuple)
+	children := []token{*prefix}
+
+	items, _ := splitRLPItems(content, off)
+	fields := txFields{}
+	for i, item := range items {
+		fields[authorizationLayout[i]] = item
+		children = append(children, shiftTokens(genToken(item, authorizationLayout[i], opts), off)...)
+		off += len(item)
+	}
+
+	delegate := common.BytesToAddress(fields.content(AUTH_ADDRESS))
+	authority, err := recoverAuthority(fields)
+	if err != nil {
+		return token{
+			Token:       hex.EncodeToString(tuple),
+			Title:       "Authorization",
+			Description: "An authorization that lets an account run the code of another address.",
+			FlavorText:  fmt.Sprintf("Can't recover who signed it: %v. Nodes skip authorizations like this one and the rest of the transaction still runs.", err),
+			Value:       "Invalid signature",
+			Length:      len(tuple),
+			Children:    children,
+		}
+	}
+
+	value := fmt.Sprintf("%s delegates to %s", authority.Hex(), delegate.Hex())
+	effect := fmt.Sprintf("Once this transaction runs, calls to %s execute the code of %s, with %s's own balance and storage.", authority.Hex(), delegate.Hex(), authority.Hex())
+	if delegate == (common.Address{}) {
+		value = fmt.Sprintf("%s clears its delegation", authority.Hex())
+		effect = fmt.Sprintf("Delegating to the zero address resets %s to a plain account without code.", authority.Hex())
+	}
+
+	children = append(children, token{
+		Title:       "Authority",
+		Description: "The account that signed this authorization, recovered from the signature over keccak256(0x05 || rlp([chain_id, address, nonce])).",
+		FlavorText:  effect,
+		Value:       authority.Hex(),
+		Kind:        kindDerived,
+	})
+
+	return token{
+		Token:       hex.EncodeToString(tuple),
+		Title:       "Authorization",
+		Description: "An account owner's signed permission for their account to run the code of another address. Its code is set to the delegation designator 0xef0100 followed by that address.",
+		FlavorText:  effect + "\nThe account keeps its private key, so the owner can still send transactions or sign a new authorization to change or clear the delegation.",
+		Value:       value,
+		Length:      len(tuple),
+		Children:    children,
+	}
+}
+
+// recoverAuthority recovers the signer of an authorization tuple
+func recoverAuthority(fields txFields) (common.Address, error) {
+	msg, err := rlp.EncodeToBytes([]rlp.RawValue{fields[AUTH_CHAIN_ID], fields[AUTH_ADDRESS], fields[AUTH_NONCE]})
+	if err != nil {
+		return common.Address{}, err
+	}
+	hash := crypto.Keccak256(append([]byte{authorizationMagic}, msg...))
+	return recoverSigner(hash, fields.content(SIG_R), fields.content(SIG_S), fields.int(Y_PARITY))
+}
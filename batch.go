@@ -0,0 +1,91 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+)
+
+// maxBatchSize caps how many inputs a single /batch request can carry
+const maxBatchSize = 1000
+
+// batchConcurrency is how many items of a batch are explained at once.
+// Set with the BATCH_CONCURRENCY environment variable.
+var batchConcurrency = 8
+
+// batchResult is one entry of a /batch response. Exactly one of Result and Error is set.
+type batchResult struct {
+	Result *response   `json:"result,omitempty"`
+	Error  *parseError `json:"error,omitempty"`
+}
+
+// handleBatch explains an array of requests. Results come back in the same order
+// and an item that fails gets an error entry instead of failing the whole batch.
+func handleBatch(w http.ResponseWriter, r *http.Request) {
+	allowCORS(w)
+	if r.Method == "OPTIONS" {
+		return
+	}
+
+	dec := json.NewDecoder(r.Body)
+	var reqs []request
+	if err := dec.Decode(&reqs); err != nil {
+		writeError(w, http.StatusBadRequest, newParseError(errBadRequest, -1, "request body must be a JSON array of requests: %v", err))
+		return
+	}
+	if len(reqs) > maxBatchSize {
+		writeError(w, http.StatusBadRequest, newParseError(errBadRequest, -1, "a batch can hold at most %d requests but this one has %d", maxBatchSize, len(reqs)))
+		return
+	}
+
+	fmt.Printf("Received batch of %d\n", len(reqs))
+
+	res, err := json.MarshalIndent(explainBatch(reqs, batchConcurrency), "", "	")
+	if err != nil {
+		panic(err)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(res)
+}
+
+// explainBatch explains every request, running at most limit at a time
+func explainBatch(reqs []request, limit int) []batchResult {
+	if limit < 1 {
+		limit = 1
+	}
+
+	results := make([]batchResult, len(reqs))
+	sem := make(chan struct{}, limit)
+	var wg sync.WaitGroup
+	for i := range reqs {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			results[i] = explainOne(reqs[i])
+		}(i)
+	}
+	wg.Wait()
+	return results
+}
+
+// explainOne explains a single batch item. A panicking parser only fails its own item.
+func explainOne(req request) (res batchResult) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("Panic explaining %q: %v\n", req.Input, r)
+			res = batchResult{Error: newParseError(errInternal, -1, "something went wrong explaining this input")}
+		}
+	}()
+
+	resp, perr := explain(req)
+	if perr != nil {
+		return batchResult{Error: perr}
+	}
+	return batchResult{Result: &resp}
+}
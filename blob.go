@@ -0,0 +1,311 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// EIP-4844 sizes
+const (
+	blobSize          = 131072
+	blobFieldElements = 4096
+	kzgSize           = 48
+	blobGasPerBlob    = 131072
+	cellProofsPerBlob = 128
+)
+
+// spans longer than this are shortened in a token's hex so a blob doesn't
+// turn into 256 KiB of JSON every time it is mentioned
+const maxTokenBytes = 1024
+
+// abbreviateHex is the hex of b, cut short if b is longer than maxTokenBytes
+func abbreviateHex(b []byte) string {
+	if len(b) <= maxTokenBytes {
+		return hex.EncodeToString(b)
+	}
+	return hex.EncodeToString(b[:32]) + "..."
+}
+
+var blobWrapperLayout = txLayout{
+	name: "EIP-4844 Blob Transaction (Network Form)",
+	desc: "This is how a blob transaction travels between nodes before it is included in a block.\nThe signed transaction comes first, followed by the blobs themselves and the KZG commitments and proofs that tie them to the transaction's versioned hashes.\nOnce the transaction is in a block only the transaction part is kept, the blobs live on in the consensus layer for about 18 days.",
+}
+
+// isBlobWrapper reports whether the payload of a type 3 transaction is the network form,
+// where the transaction's fields are the first item of the list rather than the list itself.
+func isBlobWrapper(payload []byte) bool {
+	isList, headerLen, _, err := readRLPHeader(payload)
+	return err == nil && isList && headerLen < len(payload) && payload[headerLen] >= 0xc0
+}
+
+// tokenizeBlobWrapper explains the network form of a blob transaction, rlp([tx, blobs, commitments, proofs]).
+// Since EIP-7594 there may also be a wrapper version between the transaction and the blobs.
+// Offsets are relative to the start of payload.
+func tokenizeBlobWrapper(payload []byte, layout []EthField, opts options) ([]token, txFields, *parseError) {
+	_, headerLen, contentLen, _ := readRLPHeader(payload)
+	pre, _ := addRLPToken(payload)
+	toks := []token{*pre}
+
+	end := headerLen + contentLen
+	truncated := end > len(payload)
+	if truncated {
+		end = len(payload)
+	}
+
+	// the signed transaction itself
+	off := headerLen
+	_, txHeaderLen, txContentLen, _ := readRLPHeader(payload[off:end])
+	txEnd := off + txHeaderLen + txContentLen
+	if txEnd > end {
+		txEnd = end
+	}
+	txToks, fields, perr := tokenizeTxFields(payload[off:txEnd], layout, opts)
+	toks = append(toks, token{
+		Token:       hex.EncodeToString(payload[off:txEnd]),
+		Title:       "Transaction Payload",
+		Description: "The signed transaction, exactly as it is included in a block. The transaction hash and the signature only cover this part, not the blobs.",
+		Value:       fmt.Sprintf("%d bytes", txEnd-off),
+		Offset:      off,
+		Length:      txEnd - off,
+		Children:    shiftTokens(txToks, off),
+	})
+	if perr != nil {
+		perr.Offset += off
+		return toks, fields, perr
+	}
+	off = txEnd
+
+	items, err := splitRLPItems(payload[off:end], off)
+	if err != nil {
+		perr := toParseError(err, errInvalidRLP)
+		return append(toks, errorToken(payload[perr.Offset:end], perr)), fields, perr
+	}
+
+	// EIP-7594 cell proofs are marked with a version number
+	version := 0
+	if len(items) > 0 {
+		if k, content, _, _ := rlp.Split(items[0]); k != rlp.List {
+			version = int(bytesToInt(content).Int64())
+			prefix, prefixLen := addRLPToken(items[0])
+			if prefix != nil {
+				toks = append(toks, shiftTokens([]token{*prefix}, off)...)
+			}
+			toks = append(toks, token{
+				Token:       hex.EncodeToString(items[0][prefixLen:]),
+				Title:       "Wrapper Version",
+				Description: fmt.Sprintf("EIP-7594 added this version number. Version 1 means the proofs are cell proofs, %d for each blob, instead of one proof per blob.", cellProofsPerBlob),
+				Value:       fmt.Sprintf("%d", version),
+				Offset:      off + prefixLen,
+				Length:      len(items[0]) - prefixLen,
+			})
+			off += len(items[0])
+			items = items[1:]
+		}
+	}
+	if len(items) != 3 {
+		perr := newParseError(errInvalidTx, off, "The network form should have blobs, commitments and proofs after the transaction but there are %d items here.", len(items))
+		return append(toks, errorToken(payload[off:end], perr)), fields, perr
+	}
+
+	hashes, _ := splitRLPItems(fields.content(BLOB_VERSIONED_HASHES), 0)
+
+	blobs, perr := wrapperListToken(items[0], off, "Blobs", blobSize,
+		"The blobs themselves, 128 KiB each. Contracts can never read them, only their versioned hashes.",
+		func(i int, blob []byte) token {
+			used := 0
+			for j := 0; j < len(blob); j += 32 {
+				for _, b := range blob[j : j+32] {
+					if b != 0 {
+						used++
+						break
+					}
+				}
+			}
+			return token{
+				Title:       "Blob",
+				Description: fmt.Sprintf("A blob is %d field elements of 32 bytes. Each has to be a number below the BLS12-381 field modulus, so in practice only about 31 bytes of each can hold data.", blobFieldElements),
+				FlavorText:  "The blob is paid for with blob gas whether or not it is full.",
+				Value:       fmt.Sprintf("%d KiB, %d of %d field elements are non-zero", len(blob)/1024, used, blobFieldElements),
+			}
+		})
+	if perr != nil {
+		return append(toks, errorToken(payload[perr.Offset:end], perr)), fields, perr
+	}
+	toks = append(toks, shiftTokens([]token{blobs}, off)...)
+	off += len(items[0])
+
+	commitments, perr := wrapperListToken(items[1], off, "KZG Commitments", kzgSize,
+		"A KZG commitment for each blob. Hashing a commitment gives the blob's versioned hash, which is what the transaction signs.",
+		func(i int, commitment []byte) token {
+			vh := kzgToVersionedHash(commitment)
+			flavor := fmt.Sprintf("Its versioned hash is 0x%x, but the transaction only has %d versioned hashes.", vh, len(hashes))
+			if i < len(hashes) {
+				_, want, _, _ := rlp.Split(hashes[i])
+				if string(want) == string(vh) {
+					flavor = fmt.Sprintf("Its versioned hash 0x%x matches versioned hash %d of the transaction.", vh, i)
+				} else {
+					flavor = fmt.Sprintf("Its versioned hash is 0x%x but versioned hash %d of the transaction is 0x%x, so nodes would reject this.", vh, i, want)
+				}
+			}
+			return token{
+				Title:       "KZG Commitment",
+				Description: "A 48 byte BLS12-381 curve point that commits to the blob. It is short, but no other blob has the same commitment.",
+				FlavorText:  flavor,
+				Value:       "0x" + hex.EncodeToString(commitment),
+			}
+		})
+	if perr != nil {
+		return append(toks, errorToken(payload[perr.Offset:end], perr)), fields, perr
+	}
+	toks = append(toks, shiftTokens([]token{commitments}, off)...)
+	off += len(items[1])
+
+	proofDesc := "A KZG proof for each blob, which lets nodes check that the blob matches its commitment without trusting the sender."
+	if version == 1 {
+		proofDesc = fmt.Sprintf("KZG cell proofs, %d for each blob. Each proves one cell of the blob's erasure coded extension, so nodes can sample blobs instead of downloading them whole.", cellProofsPerBlob)
+	}
+	proofs, perr := wrapperListToken(items[2], off, "KZG Proofs", kzgSize, proofDesc,
+		func(i int, proof []byte) token {
+			title := "KZG Proof"
+			if version == 1 {
+				title = "KZG Cell Proof"
+			}
+			return token{
+				Title:       title,
+				Description: "A 48 byte BLS12-381 curve point proving that a blob and its commitment belong together.",
+				Value:       "0x" + hex.EncodeToString(proof),
+			}
+		})
+	if perr != nil {
+		return append(toks, errorToken(payload[perr.Offset:end], perr)), fields, perr
+	}
+	toks = append(toks, shiftTokens([]token{proofs}, off)...)
+	off += len(items[2])
+
+	if truncated {
+		perr := newParseError(errTruncated, off, "The input ends here but the RLP prefix promised %d bytes.", contentLen)
+		return append(toks, errorToken(nil, perr)), fields, perr
+	}
+	if end < len(payload) {
+		perr := newParseError(errInvalidTx, end, "Unexpected bytes after the end of the transaction list.")
+		return append(toks, errorToken(payload[end:], perr)), fields, perr
+	}
+	return toks, fields, nil
+}
+
+// wrapperListToken explains one of the lists of the network form, whose items must all be size bytes long.
+// explainItem gives the title, description and value of the i'th item. The token's offsets are
+// relative to the start of enc, errors point into the input using off, the offset of enc.
+func wrapperListToken(enc []byte, off int, title string, size int, desc string, explainItem func(i int, item []byte) token) (token, *parseError) {
+	if err := validRLP(enc, off); err != nil {
+		return token{}, toParseError(err, errInvalidRLP)
+	}
+	k, content, _, _ := rlp.Split(enc)
+	if k != rlp.List {
+		return token{}, newParseError(errInvalidTx, off, "The %s should be an RLP 'list' but this is an RLP 'string'.", title)
+	}
+
+	prefix, prefixLen := addRLPToken(enc)
+	children := []token{*prefix}
+	items, _ := splitRLPItems(content, prefixLen)
+	itemOff := prefixLen
+	for i, item := range items {
+		k, body, _, _ := rlp.Split(item)
+		if k == rlp.List || len(body) != size {
+			return token{}, newParseError(errInvalidTx, off+itemOff, "Each of the %s should be %d bytes.", title, size)
+		}
+		itemPrefix, itemPrefixLen := addRLPToken(item)
+		tok := explainItem(i, body)
+		tok.Token = abbreviateHex(body)
+		tok.Offset = itemPrefixLen
+		tok.Length = len(body)
+		children = append(children, shiftTokens([]token{*itemPrefix, tok}, itemOff)...)
+		itemOff += len(item)
+	}
+
+	return token{
+		Token:       abbreviateHex(enc),
+		Title:       title,
+		Description: desc,
+		Value:       fmt.Sprintf("%d items", len(items)),
+		Length:      len(enc),
+		Children:    children,
+	}, nil
+}
+
+// kzgToVersionedHash is the hash a transaction signs for a blob: the version byte
+// 0x01 followed by the last 31 bytes of the sha256 of the blob's commitment
+func kzgToVersionedHash(commitment []byte) []byte {
+	h := sha256.Sum256(commitment)
+	h[0] = 0x01
+	return h[:]
+}
+
+// checkVersionedHashes makes sure enc is an RLP list of 32 byte hashes.
+// off is the offset of enc in the input.
+func checkVersionedHashes(enc []byte, off int) *parseError {
+	if err := validRLP(enc, off); err != nil {
+		return toParseError(err, errInvalidRLP)
+	}
+	_, content, _, _ := rlp.Split(enc)
+	itemOff := off + len(enc) - len(content)
+	items, _ := splitRLPItems(content, itemOff)
+	if len(items) == 0 {
+		return newParseError(errInvalidTx, off, "A blob transaction has to carry at least one blob, but it has no versioned hashes.")
+	}
+	for i, item := range items {
+		if k, hash, _, _ := rlp.Split(item); k == rlp.List || len(hash) != 32 {
+			return newParseError(errInvalidTx, itemOff, "Blob versioned hash %d should be 32 bytes.", i)
+		}
+		itemOff += len(item)
+	}
+	return nil
+}
+
+// versionedHashTokens explains each hash of a list that passed checkVersionedHashes.
+// Offsets are relative to the start of enc.
+func versionedHashTokens(enc []byte) []token {
+	_, content, _, _ := rlp.Split(enc)
+	off := len(enc) - len(content)
+	items, _ := splitRLPItems(content, off)
+
+	var toks []token
+	for i, item := range items {
+		_, hash, _, _ := rlp.Split(item)
+		prefix, prefixLen := addRLPToken(item)
+
+		version := "0x01 is the only version so far, KZG commitments hashed with sha256."
+		if hash[0] != 0x01 {
+			version = fmt.Sprintf("0x%02x is not a known version, nodes only accept 0x01.", hash[0])
+		}
+		toks = append(toks, shiftTokens([]token{*prefix, {
+			Token:       hex.EncodeToString(hash),
+			Title:       "Blob Versioned Hash",
+			Description: "Identifies one of the blobs carried with this transaction. Contracts can read it with the BLOBHASH opcode, the blob itself stays out of the EVM.",
+			Value:       fmt.Sprintf("Blob %d: 0x%x", i, hash),
+			Offset:      prefixLen,
+			Length:      len(hash),
+			Children: []token{{
+				Token:       hex.EncodeToString(hash[:1]),
+				Title:       "Version",
+				Description: "The first byte says how the rest of the hash was made, so the commitment scheme can change later without changing the hash size.",
+				FlavorText:  version,
+				Value:       fmt.Sprintf("0x%02x", hash[0]),
+				Offset:      prefixLen,
+				Length:      1,
+			}, {
+				Token:       hex.EncodeToString(hash[1:]),
+				Title:       "Commitment Hash",
+				Description: "The last 31 bytes of the sha256 hash of the blob's 48 byte KZG commitment.",
+				Value:       "0x" + hex.EncodeToString(hash[1:]),
+				Offset:      prefixLen + 1,
+				Length:      len(hash) - 1,
+			}},
+		}}, off)...)
+		off += len(item)
+	}
+	return toks
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// calldataParser explains the data of a contract call on its own, like the input
+// field of a transaction from a block explorer or a wallet's signing prompt.
+type calldataParser struct{}
+
+func init() {
+	registerParser(parserInfo{
+		Name:        "calldata",
+		Type:        "Contract Call",
+		Priority:    150,
+		Description: "The data of a contract call: a 4 byte function selector and the ABI encoded arguments. Uses the contract ABI from the request if there is one.",
+	}, &calldataParser{})
+}
+
+func (c *calldataParser) understands(s string) bool {
+	buf, err := hex.DecodeString(strings.TrimPrefix(s, "0x"))
+	if err != nil || len(buf) < 4 {
+		return false
+	}
+	// arguments are always whole words, anything else has to be a function we know
+	_, known := functionSignatures[hex.EncodeToString(buf[:4])]
+	return known || (len(buf)-4)%32 == 0
+}
+
+func (c *calldataParser) confidence(s string) float64 {
+	buf, err := hex.DecodeString(strings.TrimPrefix(s, "0x"))
+	if err != nil || len(buf) < 4 {
+		return 0
+	}
+	// only the shape fits, which plenty of other data does by chance
+	if _, known := functionSignatures[hex.EncodeToString(buf[:4])]; !known {
+		return 0.4
+	}
+	return 0.9
+}
+
+func (c *calldataParser) parse(s string, opts options) ([]token, error) {
+	buf, err := decodeHex(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(buf) < 4 {
+		return nil, newParseError(errWrongLength, -1, "A contract call starts with a 4 byte function selector but this is only %d bytes.", len(buf))
+	}
+
+	summary, toks := calldataTokens(buf, opts.contract)
+	return []token{{
+		Token:       abbreviateHex(buf),
+		Title:       "Contract Call",
+		Description: "The data sent to a contract to call one of its functions. The first 4 bytes pick the function and the rest are its arguments, ABI encoded in 32 byte words.",
+		FlavorText:  fmt.Sprintf("%d bytes of arguments. The contract decides what to do with them, nothing checks them before the call.", len(buf)-4),
+		Value:       summary,
+		Length:      len(buf),
+		Children:    toks,
+	}}, nil
+}
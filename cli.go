@@ -0,0 +1,185 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+)
+
+// printed with the name of the binary
+const usage = `usage:
+  %[1]s [serve]
+        start the HTTP server
+  %[1]s explain [-json] [-hint parser] [-base-fee wei] [-abi file] [-types types] [-no-color] [-f file] [input]
+        explain an input given as an argument, in a file or on stdin.
+        Flags go before the input, anything after it isn't read as a flag.
+  %[1]s opgen [file]
+        print the opcode switch statement for opcode.go
+`
+
+// ANSI colors
+const (
+	colorReset = "\033[0m"
+	colorDim   = "\033[2m"
+	colorBold  = "\033[1m"
+	colorError = "\033[1;31m"
+	colorWarn  = "\033[1;33m"
+)
+
+// token colors cycle through a rainbow like the web client
+var rainbow = []string{"\033[95m", "\033[91m", "\033[33m", "\033[93m", "\033[92m", "\033[94m", "\033[35m"}
+
+// runExplain is the explain command. It explains a single input and prints
+// the tokens, or the same JSON payload the server would send with --json.
+func runExplain(args []string, stdin io.Reader, out io.Writer) error {
+	fs := flag.NewFlagSet("explain", flag.ContinueOnError)
+	asJSON := fs.Bool("json", false, "print the JSON payload the server would return")
+	hint := fs.String("hint", "", "name of the parser to use instead of auto-detection")
+	file := fs.String("f", "", "read the input from this file")
+	baseFee := fs.String("base-fee", "", "block base fee in wei (or like \"12.5 gwei\") for fee explanations")
+	abiFile := fs.String("abi", "", "contract ABI JSON file for decoding calldata")
+	types := fs.String("types", "", "types of ABI encoded data, like \"(uint256,bytes,address[])\"")
+	noColor := fs.Bool("no-color", os.Getenv("NO_COLOR") != "", "disable colors")
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return nil
+		}
+		return err
+	}
+	// the flag package stops at the first argument, so flags after the input end up here
+	if fs.NArg() > 1 {
+		return fmt.Errorf("explain takes a single input but got %d arguments: %s. Flags go before the input", fs.NArg(), strings.Join(fs.Args(), " "))
+	}
+
+	var input string
+	switch {
+	case *file != "":
+		buf, err := ioutil.ReadFile(*file)
+		if err != nil {
+			return err
+		}
+		input = string(buf)
+	case fs.NArg() > 0 && fs.Arg(0) != "-":
+		input = fs.Arg(0)
+	default:
+		buf, err := ioutil.ReadAll(stdin)
+		if err != nil {
+			return err
+		}
+		input = string(buf)
+	}
+
+	req := request{Input: strings.TrimSpace(input), Hint: *hint, BaseFee: *baseFee, Types: *types}
+	if *abiFile != "" {
+		buf, err := ioutil.ReadFile(*abiFile)
+		if err != nil {
+			return err
+		}
+		req.ABI = buf
+	}
+	resp, perr := explain(req)
+
+	if *asJSON {
+		var payload interface{} = resp
+		if perr != nil {
+			payload = errorResponse{Error: perr}
+		}
+		res, err := json.MarshalIndent(payload, "", "	")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(out, string(res))
+		if perr != nil {
+			return fmt.Errorf("%s: %v", perr.Code, perr)
+		}
+		return nil
+	}
+
+	if perr != nil {
+		return perr
+	}
+
+	p := printer{w: out, color: !*noColor && isTerminal(out)}
+	for i, interp := range resp.Results {
+		if i > 0 {
+			fmt.Fprintln(out)
+		}
+		p.interpretation(interp)
+	}
+	return nil
+}
+
+// isTerminal is whether w is a terminal rather than a file or a pipe, which
+// shouldn't get escape codes
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	fi, err := f.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
+// printer pretty prints token trees to a terminal
+type printer struct {
+	w     io.Writer
+	color bool
+	n     int
+}
+
+func (p *printer) paint(color, s string) string {
+	if !p.color {
+		return s
+	}
+	return color + s + colorReset
+}
+
+func (p *printer) interpretation(interp interpretation) {
+	fmt.Fprintf(p.w, "%s (%s, confidence %.2f)\n", p.paint(colorBold, interp.Type), interp.Parser, interp.Confidence)
+	p.tokens(interp.Tokens, 1)
+	if interp.Error != nil {
+		fmt.Fprintln(p.w, p.paint(colorError, "Stopped early: "+interp.Error.Error()))
+	}
+}
+
+func (p *printer) tokens(toks []token, depth int) {
+	indent := strings.Repeat("  ", depth)
+	for _, t := range toks {
+		color := rainbow[p.n%len(rainbow)]
+		switch t.Kind {
+		case kindError:
+			color = colorError
+		case kindWarning:
+			color = colorWarn
+		}
+		p.n++
+
+		span := fmt.Sprintf("[%d+%d]", t.Offset, t.Length)
+		if t.Kind == kindDerived {
+			span = "[derived]"
+		}
+		value := t.Value
+		if t.Target != nil {
+			value += p.paint(colorDim, fmt.Sprintf(" -> [%d]", *t.Target))
+		}
+		fmt.Fprintf(p.w, "%s%s %s %s\n", indent, p.paint(colorDim, span), p.paint(color, t.Title), value)
+		for _, line := range strings.Split(t.Description, "\n") {
+			if line != "" {
+				fmt.Fprintf(p.w, "%s    %s\n", indent, p.paint(colorDim, line))
+			}
+		}
+		// derived tokens have no bytes to look at, so what they worked out matters more
+		if t.Kind == kindDerived {
+			for _, line := range strings.Split(t.FlavorText, "\n") {
+				if line != "" {
+					fmt.Fprintf(p.w, "%s    %s\n", indent, line)
+				}
+			}
+		}
+		p.tokens(t.Children, depth+1)
+	}
+}
@@ -4,6 +4,7 @@ import {
     concat,
     filter,
     find,
+    flatMap,
     get,
     map
 } from 'lodash'
@@ -40,6 +41,16 @@ const rainbowColors = {
     6: 'purple.400',
 }
 
+// tokens can nest, but the hex view only shows the innermost ones.
+// Derived tokens don't cover any bytes and warnings cover the same bytes as the
+// token before them, so neither has a place in it.
+const hexViewSkips = ['derived', 'warning']
+const flattenTokens = (tokens) => flatMap(tokens, t => t.children ? flattenTokens(t.children) : hexViewSkips.includes(t.kind) ? [] : [t])
+
+// tokens like ABI offsets point at another part of the input. While one is
+// hovered the token it points at stays lit too.
+const isHighlighted = (displayToken, tokenObj) => !displayToken || displayToken === tokenObj || displayToken.target === tokenObj.offset
+
 const App = () => {
     return (
         <ThemeProvider>
@@ -121,18 +132,15 @@ const Home = () => {
             //const goResponse = await axios.post('http://localhost:8080', { input })
             const goResponse = await axios.post('https://calm-thicket-60588.herokuapp.com/', { input })
             console.log({ responseData: goResponse.data })
-            if (typeof get(goResponse, 'data', null) === 'string') {
-                handleErrorState(true)
-                setErrorText('Sorry, I don\'t understand this format.')
-            }
             if(!errorState) {
-                setResponse(get(goResponse, 'data'))
+                // use the most confident interpretation
+                setResponse(flattenTokens(get(goResponse, 'data.results[0].tokens', [])))
                 setPage(1)
             }
         } catch (err) {
             console.log(`API err: ${err}`)
             handleErrorState(true)
-            setErrorText('Something went wrong. I\'m sorry.')
+            setErrorText(get(err, 'response.data.error.message', 'Something went wrong. I\'m sorry.'))
         }
     }, [input])
 
@@ -256,7 +264,7 @@ const Home = () => {
                                                     key={index}
                                                     font='inherit'
                                                     color={rainbowColors[index % 7]}
-                                                    opacity={!displayToken || displayToken === tokenObj ? '1': '0.4'}
+                                                    opacity={isHighlighted(displayToken, tokenObj) ? '1': '0.4'}
                                                     onMouseEnter={() => {
                                                         tokenObj.colorIndex = index % 7
                                                         setDisplayToken(tokenObj)
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// machine readable codes for the error envelope
+const (
+	errBadRequest    = "bad_request"
+	errUnknownFormat = "unknown_format"
+	errUnknownParser = "unknown_parser"
+	errInvalidInput  = "invalid_input"
+	errInvalidHex    = "invalid_hex"
+	errInvalidRLP    = "invalid_rlp"
+	errInvalidTx     = "invalid_tx"
+	errInvalidXPUB   = "invalid_xpub"
+	errBadChecksum   = "bad_checksum"
+	errTruncated     = "truncated"
+	errTxNotFound    = "tx_not_found"
+	errFetchFailed   = "fetch_failed"
+	errWrongLength   = "wrong_length"
+	errInternal      = "internal"
+)
+
+// parseError is how parsers report input they can't make sense of.
+// Offset is the byte offset into the decoded input where decoding failed,
+// or -1 when the failure isn't tied to a position.
+type parseError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Offset  int    `json:"offset"`
+	Parser  string `json:"parser,omitempty"`
+}
+
+func newParseError(code string, offset int, format string, args ...interface{}) *parseError {
+	return &parseError{
+		Code:    code,
+		Message: fmt.Sprintf(format, args...),
+		Offset:  offset,
+	}
+}
+
+func (e *parseError) Error() string {
+	if e.Offset < 0 {
+		return e.Message
+	}
+	return fmt.Sprintf("%s (at byte %d)", e.Message, e.Offset)
+}
+
+// toParseError passes parseErrors through and wraps anything else with the given code
+func toParseError(err error, code string) *parseError {
+	var pe *parseError
+	if errors.As(err, &pe) {
+		return pe
+	}
+	return newParseError(code, -1, "%v", err)
+}
+
+// errorToken marks where decoding stopped. span holds the offending bytes, which
+// start at the error's offset, and may be empty if the input simply ran out.
+func errorToken(span []byte, perr *parseError) token {
+	return token{
+		Token:       abbreviateHex(span),
+		Title:       "Error",
+		Description: perr.Message,
+		Value:       "0x" + abbreviateHex(span),
+		Kind:        kindError,
+		Offset:      perr.Offset,
+		Length:      len(span),
+	}
+}
+
+// warningToken flags span as suspicious. title names the problem and msg explains it.
+// Offsets are relative to the start of span.
+func warningToken(span []byte, title, msg string) token {
+	return token{
+		Token:       abbreviateHex(span),
+		Title:       title,
+		Description: msg,
+		Value:       "0x" + abbreviateHex(span),
+		Kind:        kindWarning,
+		Length:      len(span),
+	}
+}
+
+type errorResponse struct {
+	Error *parseError `json:"error"`
+}
+
+func writeError(w http.ResponseWriter, status int, err *parseError) {
+	res, merr := json.MarshalIndent(errorResponse{Error: err}, "", "	")
+	if merr != nil {
+		panic(merr)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(res)
+}
+
+// decodeHex decodes an optionally 0x prefixed hex string, pointing at the
+// first bad character when it can't.
+func decodeHex(s string) ([]byte, error) {
+	s = strings.TrimPrefix(s, "0x")
+	for i, c := range s {
+		if !strings.ContainsRune("0123456789abcdefABCDEF", c) {
+			return nil, newParseError(errInvalidHex, i/2, "%q is not a hex character", c)
+		}
+	}
+	if len(s)%2 != 0 {
+		return nil, newParseError(errInvalidHex, len(s)/2, "hex input has an odd number of characters (%d)", len(s))
+	}
+	return hex.DecodeString(s)
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/hex"
 	"fmt"
+	"math/big"
 	"strings"
 
 	"github.com/ethereum/go-ethereum/core/types"
@@ -22,114 +23,476 @@ const (
 	SIG_V
 	SIG_R
 	SIG_S
+	CHAIN_ID
+	ACCESS_LIST
+	Y_PARITY
+	MAX_PRIORITY_FEE_PER_GAS
+	MAX_FEE_PER_GAS
+	MAX_FEE_PER_BLOB_GAS
+	BLOB_VERSIONED_HASHES
+	AUTHORIZATION_LIST
+	AUTH_CHAIN_ID
+	AUTH_ADDRESS
+	AUTH_NONCE
+	EIP155_ZERO
+	INIT_CODE
 )
 
+func (f EthField) String() string {
+	switch f {
+	case NONCE:
+		return "Nonce"
+	case GAS_PRICE:
+		return "Gas Price"
+	case GAS_LIMIT:
+		return "Gas Limit"
+	case RECIPIENT:
+		return "Recipient"
+	case VALUE:
+		return "Value"
+	case DATA:
+		return "Data"
+	case SIG_V:
+		return "Signature V"
+	case SIG_R:
+		return "Signature R"
+	case SIG_S:
+		return "Signature S"
+	case CHAIN_ID:
+		return "Chain ID"
+	case ACCESS_LIST:
+		return "Access List"
+	case Y_PARITY:
+		return "Signature Y Parity"
+	case MAX_PRIORITY_FEE_PER_GAS:
+		return "Max Priority Fee Per Gas"
+	case MAX_FEE_PER_GAS:
+		return "Max Fee Per Gas"
+	case MAX_FEE_PER_BLOB_GAS:
+		return "Max Fee Per Blob Gas"
+	case BLOB_VERSIONED_HASHES:
+		return "Blob Versioned Hashes"
+	case AUTHORIZATION_LIST:
+		return "Authorization List"
+	case AUTH_CHAIN_ID:
+		return "Authorization Chain ID"
+	case AUTH_ADDRESS:
+		return "Delegate Address"
+	case AUTH_NONCE:
+		return "Authorization Nonce"
+	case EIP155_ZERO:
+		return "EIP-155 Zero"
+	case INIT_CODE:
+		return "Init Code"
+	}
+	return fmt.Sprintf("EthField(%d)", int(f))
+}
+
+// fields that are RLP lists rather than strings
+func (f EthField) isList() bool {
+	return f == ACCESS_LIST || f == BLOB_VERSIONED_HASHES || f == AUTHORIZATION_LIST
+}
+
+// fields that are part of the sender's signature rather than what was signed
+func (f EthField) isSignature() bool {
+	return f == SIG_V || f == SIG_R || f == SIG_S || f == Y_PARITY
+}
+
+// txLayout is the name and field order of one kind of transaction.
+// typ is the EIP-2718 type byte, 0 for legacy transactions. Unsigned
+// layouts are transactions before they are signed, see unsigned.go.
+type txLayout struct {
+	name     string
+	desc     string
+	typ      byte
+	fields   []EthField
+	unsigned bool
+}
+
+var legacyLayout = txLayout{
+	name:   "Legacy Transaction",
+	desc:   "A legacy Ethereum transaction is an RLP 'list' of 9 fields.\nThe fields are the transaction itself followed by the (v, r, s) signature of the sender.",
+	fields: []EthField{NONCE, GAS_PRICE, GAS_LIMIT, RECIPIENT, VALUE, DATA, SIG_V, SIG_R, SIG_S},
+}
+
+// EIP-2718 transaction types we can name, whether or not we know their layout
+var txTypeNames = map[byte]string{
+	0x01: "EIP-2930 Access List",
+	0x02: "EIP-1559 Dynamic Fee",
+	0x03: "EIP-4844 Blob",
+	0x04: "EIP-7702 Set Code",
+}
+
+// networks we can name by chain ID
+var chainNames = map[uint64]string{
+	1:        "Ethereum Mainnet",
+	3:        "Ropsten",
+	4:        "Rinkeby",
+	5:        "Goerli",
+	10:       "OP Mainnet",
+	42:       "Kovan",
+	56:       "BNB Smart Chain",
+	61:       "Ethereum Classic",
+	100:      "Gnosis",
+	137:      "Polygon",
+	250:      "Fantom",
+	324:      "zkSync Era",
+	1337:     "Local Development Chain",
+	8453:     "Base",
+	17000:    "Holesky",
+	31337:    "Hardhat / Anvil",
+	42161:    "Arbitrum One",
+	43114:    "Avalanche C-Chain",
+	59144:    "Linea",
+	534352:   "Scroll",
+	560048:   "Hoodi",
+	11155111: "Sepolia",
+}
+
+// describeChainID is the chain ID with the name of its network, if we know it
+func describeChainID(id *big.Int) string {
+	if !id.IsUint64() {
+		return id.String() + " (unknown network)"
+	}
+	if name, ok := chainNames[id.Uint64()]; ok {
+		return fmt.Sprintf("%s (%s)", id.String(), name)
+	}
+	return id.String() + " (unknown network)"
+}
+
+// field layouts of typed transactions, by type byte
+var typedLayouts = map[byte]txLayout{
+	0x01: {
+		name:   "EIP-2930 Access List Transaction",
+		desc:   "An EIP-2930 transaction adds a chain ID and an access list to the legacy fields.\nThe access list declares up front which accounts and storage slots the transaction will touch, in exchange for cheaper access to them.",
+		fields: []EthField{CHAIN_ID, NONCE, GAS_PRICE, GAS_LIMIT, RECIPIENT, VALUE, DATA, ACCESS_LIST, Y_PARITY, SIG_R, SIG_S},
+	},
+	0x02: {
+		name:   "EIP-1559 Dynamic Fee Transaction",
+		desc:   "An EIP-1559 transaction replaces the single gas price with two fee caps.\nEvery block has a base fee that is burned, and the sender adds a tip on top for the block producer. The sender pays at most the max fee per gas, however high the base fee gets.",
+		fields: []EthField{CHAIN_ID, NONCE, MAX_PRIORITY_FEE_PER_GAS, MAX_FEE_PER_GAS, GAS_LIMIT, RECIPIENT, VALUE, DATA, ACCESS_LIST, Y_PARITY, SIG_R, SIG_S},
+	},
+	0x03: {
+		name:   "EIP-4844 Blob Transaction",
+		desc:   "An EIP-4844 transaction carries blobs, large chunks of data that are cheap because the EVM can't read them and nodes only keep them for a few weeks.\nThe transaction itself only holds a versioned hash for each blob. The blobs travel next to it, see the network form.",
+		fields: []EthField{CHAIN_ID, NONCE, MAX_PRIORITY_FEE_PER_GAS, MAX_FEE_PER_GAS, GAS_LIMIT, RECIPIENT, VALUE, DATA, ACCESS_LIST, MAX_FEE_PER_BLOB_GAS, BLOB_VERSIONED_HASHES, Y_PARITY, SIG_R, SIG_S},
+	},
+	0x04: {
+		name:   "EIP-7702 Set Code Transaction",
+		desc:   "An EIP-7702 transaction lets ordinary accounts (EOAs) take on the code of a contract.\nIt carries a list of authorizations, each signed by an account owner, that point the account at a contract whose code it will run from then on. The sender pays for them, so it doesn't have to be any of the accounts being set up.",
+		fields: []EthField{CHAIN_ID, NONCE, MAX_PRIORITY_FEE_PER_GAS, MAX_FEE_PER_GAS, GAS_LIMIT, RECIPIENT, VALUE, DATA, ACCESS_LIST, AUTHORIZATION_LIST, Y_PARITY, SIG_R, SIG_S},
+	},
+}
+
+// txFields holds the raw RLP encoding of each field that was decoded,
+// for explanations that need more than one field
+type txFields map[EthField][]byte
+
+// content is the value of field f without its RLP prefix
+func (t txFields) content(f EthField) []byte {
+	_, content, _, _ := rlp.Split(t[f])
+	return content
+}
+
+// isCreation is whether the transaction creates a contract, which it does when the recipient is empty
+func (t txFields) isCreation() bool {
+	_, ok := t[RECIPIENT]
+	return ok && len(t.content(RECIPIENT)) == 0
+}
+
+// int is the value of numeric field f, 0 if it is missing
+func (t txFields) int(f EthField) *big.Int {
+	return bytesToInt(t.content(f))
+}
+
+// length of a 0x prefixed transaction hash
+const txHashLen = len("0xc45367afb97f4e79fe6cccfed0bea22a8c63d6fbd7ec4f85aa2541d05075f8af")
+
 // TODO: move this to own package and export
 type ethTxParser struct{}
 
+func init() {
+	registerParser(parserInfo{
+		Name:        "eth-tx",
+		Type:        "Eth Transaction",
+		Priority:    300,
+		Description: "RLP encoded Ethereum transactions, signed or still waiting for a signature, or a transaction hash to look up.",
+	}, &ethTxParser{})
+}
+
+// isTxHash reports whether s looks like a 0x prefixed transaction hash
+func isTxHash(s string) bool {
+	if len(s) != txHashLen || !strings.HasPrefix(s, "0x") {
+		return false
+	}
+	_, err := hex.DecodeString(s[2:])
+	return err == nil
+}
+
 func (e *ethTxParser) understands(s string) bool {
 	tx := &types.Transaction{}
 
-	// hacky we got a txID and need to look up the raw txn
-	if len(s) == len("0xc45367afb97f4e79fe6cccfed0bea22a8c63d6fbd7ec4f85aa2541d05075f8af") {
-		raw := etherscanCrawlRaw(s)
-		if len(raw) > 20 {
-			return true
-		}
-		return false
+	// a txID we can look up the raw txn for. It's only fetched once we're asked to parse it.
+	if isTxHash(s) {
+		return txSource != nil
 	}
 
 	rawTx := strings.TrimPrefix(s, "0x")
 	buf, err := hex.DecodeString(rawTx)
-	if err != nil {
+	if err != nil || len(buf) == 0 {
 		return false
 	}
 
+	// EIP-2718 typed transaction: a type byte followed by an RLP list
+	if buf[0] <= 0x7f {
+		_, known := txTypeNames[buf[0]]
+		return known && len(buf) > 1 && buf[1] >= 0xc0
+	}
+
 	r := bytes.NewBuffer(buf)
 	stream := rlp.NewStream(r, 0)
 	err = tx.DecodeRLP(stream)
-	if err != nil {
-		return false
+	if err == nil {
+		return true
+	}
+
+	// transactions that still have to be signed
+	if legacyLayoutFor(buf).unsigned {
+		return true
 	}
 
-	return true
+	// signed transactions are always long lists. If it starts like one but
+	// isn't valid RLP we can at least explain how far it got.
+	return buf[0] >= 0xf8 && validRLP(buf, 0) != nil
+}
+
+func (e *ethTxParser) confidence(s string) float64 {
+	// a bare hash could just as well be a private key or a storage slot
+	if isTxHash(s) {
+		return 0.5
+	}
+	// a short RLP list of 6 items could be many other things
+	if buf, err := hex.DecodeString(strings.TrimPrefix(s, "0x")); err == nil && len(buf) > 0 && buf[0] > 0x7f {
+		if legacyLayoutFor(buf).name == unsignedLegacyLayout.name {
+			return 0.8
+		}
+	}
+	return 0.95
 }
 
-func (e *ethTxParser) parse(s string) ([]token, error) {
+func (e *ethTxParser) parse(s string, opts options) ([]token, error) {
 
-	tx := &types.Transaction{}
-	var rawTx string
+	var buf []byte
+	var err error
 
-	// hacky we got a txID and need to look up the raw txn
-	// TODO: need to deal with network failure for etherscan
-	if len(s) == len("0xc45367afb97f4e79fe6cccfed0bea22a8c63d6fbd7ec4f85aa2541d05075f8af") {
-		rawTx = strings.TrimPrefix(etherscanCrawlRaw(s), "0x")
+	// we got a txID and need to look up the raw txn
+	if isTxHash(s) {
+		if txSource == nil {
+			return nil, newParseError(errFetchFailed, -1, "can't look up transaction hashes, no transaction source is configured")
+		}
+		buf, err = txSource.RawTransaction(s)
 	} else {
-		rawTx = strings.TrimPrefix(s, "0x")
+		buf, err = decodeHex(s)
 	}
-
-	buf, err := hex.DecodeString(rawTx)
 	if err != nil {
 		return nil, err
 	}
-
-	r := bytes.NewBuffer(buf)
-	stream := rlp.NewStream(r, 0)
-	err = tx.DecodeRLP(stream)
-	if err != nil {
-		return nil, err
+	if len(buf) == 0 {
+		return nil, newParseError(errTruncated, 0, "no data to decode")
 	}
 
+	// EIP-2718: legacy transactions start with an RLP list prefix (>= 0xc0),
+	// anything from 0x00 to 0x7f is the type byte of a typed transaction
+	var layout txLayout
 	var toks []token
+	var fields txFields
+	var perr *parseError
+	if buf[0] <= 0x7f {
+		layout, toks, fields, perr = tokenizeTypedTx(buf, opts)
+	} else {
+		layout = legacyLayoutFor(buf)
+		toks, fields, perr = tokenizeTxFields(buf, layout.fields, opts)
+	}
+
+	txTok := token{
+		Token:       abbreviateHex(buf),
+		Title:       layout.name,
+		Description: layout.desc,
+		Value:       fmt.Sprintf("%d bytes", len(buf)),
+		Length:      len(buf),
+		Children:    toks,
+	}
+	if perr != nil {
+		return []token{txTok}, perr
+	}
+
+	// explanations that don't belong to any one span of the input
+	var derived []token
+	if layout.unsigned {
+		derived = unsignedTokens(layout, buf)
+	} else {
+		derived = hashTokens(layout, fields)
+		derived = append(derived, senderTokens(layout, fields)...)
+	}
+	derived = append(derived, feeTokens(fields, opts.baseFee)...)
+	derived = append(derived, blobGasTokens(fields)...)
+	return append([]token{txTok}, derived...), nil
+}
+
+// tokenizeTypedTx explains an EIP-2718 envelope, the type byte followed by the
+// payload, and returns the layout the payload was decoded with.
+func tokenizeTypedTx(buf []byte, opts options) (txLayout, []token, txFields, *parseError) {
+	typ := buf[0]
+	name, known := txTypeNames[typ]
+	if !known {
+		name = "Unknown"
+	}
+
+	typeTok := token{
+		Token:       hex.EncodeToString(buf[:1]),
+		Title:       "Transaction Type",
+		Description: "EIP-2718 wraps new kinds of transactions in an envelope: a single type byte followed by the payload for that type.\nLegacy transactions always start with an RLP list prefix (0xc0 or higher), so a first byte from 0x00 to 0x7f can only be a type.",
+		FlavorText:  "The type decides how the rest of the bytes are laid out and how the transaction is signed.",
+		Value:       fmt.Sprintf("%d (%s)", typ, name),
+		Length:      1,
+	}
+	toks := []token{typeTok}
+
+	layout, ok := typedLayouts[typ]
+	layout.typ = typ
+	if !ok {
+		layout = txLayout{name: name + " Transaction", desc: "An EIP-2718 typed transaction.", typ: typ}
+		perr := newParseError(errInvalidTx, 1, "Don't know the field layout of type %d transactions.", typ)
+		return layout, append(toks, errorToken(buf[1:], perr)), nil, perr
+	}
+
+	var fieldToks []token
+	var fields txFields
+	var perr *parseError
+	if typ == 0x03 && isBlobWrapper(buf[1:]) {
+		fieldToks, fields, perr = tokenizeBlobWrapper(buf[1:], layout.fields, opts)
+		layout.name, layout.desc = blobWrapperLayout.name, blobWrapperLayout.desc
+	} else {
+		layout = typedLayoutFor(layout, buf[1:])
+		fieldToks, fields, perr = tokenizeTxFields(buf[1:], layout.fields, opts)
+	}
+	toks = append(toks, shiftTokens(fieldToks, 1)...)
+	if perr != nil {
+		perr.Offset++
+	}
+	return layout, toks, fields, perr
+}
+
+// tokenizeTxFields explains the RLP list of transaction fields in buf, following layout.
+// Decoding stops at the first missing or malformed field. The tokens decoded up to
+// that point are still returned, ending with an error token that explains what went wrong.
+// Offsets are relative to the start of buf. The fields that were decoded are returned by kind.
+func tokenizeTxFields(buf []byte, layout []EthField, opts options) ([]token, txFields, *parseError) {
+	isList, headerLen, contentLen, err := readRLPHeader(buf)
+	if err != nil {
+		perr := rlpError(err, 0)
+		return []token{errorToken(buf, perr)}, nil, perr
+	}
+	if !isList {
+		perr := newParseError(errInvalidTx, 0, "A transaction is an RLP 'list' but this is an RLP 'string'.")
+		return []token{errorToken(buf[:headerLen], perr)}, nil, perr
+	}
+	fields := txFields{}
 
 	// first rlp node pre-nonce
-	// TODO: probaly some edge case i'm missing
-	prefix := buf[0]
-	l := prefix - 0xf7
-	llen := buf[1 : 1+l]
-	pre := token{
-		Token:       hex.EncodeToString(buf[0 : 1+l]),
-		Title:       "RLP Prefix",
-		Description: fmt.Sprintf("RLP is an encoding/decoding algorithm that helps Ethereum to serialize data.\nThis is an RLP 'list' with total length > 55 bytes.\n The first byte (0x%x - 0xF7) tells us the length of the length (%d bytes).\nThe actual length of the list in bytes is %s bytes (0x%x).", prefix, l, bytesToInt(llen), llen),
-		Value:       "0x" + hex.EncodeToString(buf[0:1+l]),
-	}
-	toks = append(toks, pre)
-
-	/*
-			tok := &token{
-			Token:       hex.EncodeToString(enc[:1+l]),
-			Title:       "RLP Length Prefix",
-			Description: fmt.Sprintf("This is an RLP 'string' with length > 55 bytes.\nThe first byte (0x%x-0xB7) tells us the length of the length (%d bytes).\nThe actual field length is %s bytes (0x%x)", prefix, l, bytesToInt(fieldLen).String(), fieldLen),
-			Value:       hex.EncodeToString(enc[:1+l]),
-		}
-	*/
+	pre, _ := addRLPToken(buf)
+	toks := []token{*pre}
+
+	truncated := headerLen+contentLen > len(buf)
+	end := headerLen + contentLen
+	if truncated {
+		end = len(buf)
+	}
 
 	// add the other fields and their rlp prefixes
-	toks = append(toks, genToken(tx.Nonce(), NONCE)...)
-	toks = append(toks, genToken(tx.GasPrice(), GAS_PRICE)...)
-	toks = append(toks, genToken(tx.Gas(), GAS_LIMIT)...)
-	// empty slice if contract creation
-	if tx.To() != nil {
-		toks = append(toks, genToken(tx.To().Bytes(), RECIPIENT)...)
-	} else {
-		toks = append(toks, genToken([]byte{}, RECIPIENT)...)
+	off := headerLen
+	for _, f := range layout {
+		if off == end {
+			perr := newParseError(errInvalidTx, off, "The transaction list ends here but the %s field was expected next.", f)
+			if truncated {
+				perr = newParseError(errTruncated, off, "The input ends here but the %s field was expected next. The RLP prefix promised %d bytes of fields.", f, contentLen)
+			}
+			return append(toks, errorToken(nil, perr)), fields, perr
+		}
+
+		_, _, rest, err := rlp.Split(buf[off:end])
+		if err != nil {
+			perr := rlpError(err, off)
+			perr.Message = fmt.Sprintf("Expected the %s field here. %s", f, perr.Message)
+			return append(toks, errorToken(buf[off:end], perr)), fields, perr
+		}
+		field := buf[off : end-len(rest)]
+		if perr := checkField(field, f, off); perr != nil {
+			return append(toks, errorToken(field, perr)), fields, perr
+		}
+
+		fields[f] = field
+		// without a recipient the data isn't a call but the code of the new contract
+		kind := f
+		if f == DATA && fields.isCreation() {
+			kind = INIT_CODE
+		}
+		toks = append(toks, shiftTokens(genToken(field, kind, opts), off)...)
+		off += len(field)
 	}
-	toks = append(toks, genToken(tx.Value(), VALUE)...)
-	toks = append(toks, genToken(tx.Data(), DATA)...)
-	sigV, sigR, sigS := tx.RawSignatureValues()
-	toks = append(toks, genToken(sigV.Bytes(), SIG_V)...)
-	toks = append(toks, genToken(sigR.Bytes(), SIG_R)...)
-	toks = append(toks, genToken(sigS.Bytes(), SIG_S)...)
 
-	return toks, nil
+	if off < end {
+		perr := newParseError(errInvalidTx, off, "Unexpected extra data after the %s field, the last one this kind of transaction has.", layout[len(layout)-1])
+		return append(toks, errorToken(buf[off:end], perr)), fields, perr
+	}
+	if end < len(buf) {
+		perr := newParseError(errInvalidTx, end, "Unexpected bytes after the end of the transaction list.")
+		return append(toks, errorToken(buf[end:], perr)), fields, perr
+	}
+	return toks, fields, nil
 }
 
-func genToken(val interface{}, f EthField) []token {
+// checkField makes sure an RLP item has the right shape for field f before genToken explains it.
+// off is the offset of the item in the input.
+func checkField(enc []byte, f EthField, off int) *parseError {
+	k, content, _, _ := rlp.Split(enc)
+	if k == rlp.List && !f.isList() {
+		return newParseError(errInvalidTx, off, "The %s field should be an RLP 'string' but this is an RLP 'list'.", f)
+	}
+	if k != rlp.List && f.isList() {
+		return newParseError(errInvalidTx, off, "The %s field should be an RLP 'list' but this is an RLP 'string'.", f)
+	}
 
-	enc, err := rlp.EncodeToBytes(val)
-	if err != nil {
-		panic(err)
+	switch f {
+	case ACCESS_LIST:
+		return checkAccessList(enc, off)
+	case BLOB_VERSIONED_HASHES:
+		return checkVersionedHashes(enc, off)
+	case AUTHORIZATION_LIST:
+		return checkAuthorizationList(enc, off)
+	case AUTH_ADDRESS:
+		if len(content) != 20 {
+			return newParseError(errInvalidTx, off, "The delegate address should be 20 bytes but it is %d bytes.", len(content))
+		}
+	case RECIPIENT:
+		if len(content) != 0 && len(content) != 20 {
+			return newParseError(errInvalidTx, off, "The recipient should be a 20 byte address (or empty for contract creation) but it is %d bytes.", len(content))
+		}
+	case DATA:
+	default:
+		if len(content) > 32 {
+			return newParseError(errInvalidTx, off, "The %s field is a number of at most 32 bytes but this is %d bytes.", f, len(content))
+		}
+		if len(content) > 0 && content[0] == 0 {
+			return newParseError(errInvalidTx, off, "The %s field is a number so it can't start with a zero byte. RLP numbers must use the shortest encoding.", f)
+		}
 	}
+	return nil
+}
+
+// genToken explains a single RLP encoded field of a transaction.
+// Offsets of the returned tokens are relative to the start of enc.
+func genToken(enc []byte, f EthField, opts options) []token {
 
 	var toks []token
 
@@ -144,12 +507,19 @@ func genToken(val interface{}, f EthField) []token {
 
 	//fmt.Printf("prefixLen: %d, enc: %s, res: %s\n", prefixLen, hex.EncodeToString(enc), hex.EncodeToString(body))
 
+	// 0x80 is the empty string, which is also how RLP encodes zero
+	num := bytesToInt(body)
+	if rlpTok == nil && body[0] == 0x80 {
+		num.SetInt64(0)
+	}
+
 	// Add token for actual field
 	var (
 		title    string
 		desc     string
 		longDesc string
 		value    string
+		children []token
 	)
 	switch f {
 	case NONCE:
@@ -165,7 +535,7 @@ func genToken(val interface{}, f EthField) []token {
 		title = "Gas Price"
 		desc = "The price of gas (in wei) that the sender is willing to pay."
 		longDesc = ""
-		value = fmt.Sprintf("%s (0x%x)", bytesToInt(body).String(), body)
+		value = fmt.Sprintf("%s (0x%x)", num.String(), body)
 	case GAS_LIMIT:
 		title = "Gas Limit"
 		desc = "The maximum amount of gas the originator is willing to pay for this transaction."
@@ -194,20 +564,37 @@ func genToken(val interface{}, f EthField) []token {
 		title = "Data"
 		desc = "Data being sent to a contract function. The first 4 bytes are known as the 'function selector'."
 		longDesc = ""
-		if rlpTok == nil {
+		if rlpTok == nil && body[0] == 0x80 {
 			value = "No Data"
 		} else {
-			if len(body) > 20 {
-				value = "0x" + hex.EncodeToString(body[:20]) + "..."
-			} else {
-				value = "0x" + hex.EncodeToString(body)
-			}
+			value, children = calldataTokens(body, opts.contract)
+			children = shiftTokens(children, prefixLen)
+		}
+	case INIT_CODE:
+		title = "Init Code"
+		desc = "The recipient is empty, so the data is the init code of the contract being created. It runs once, as the constructor, and returns the code the new contract will have."
+		longDesc = "Solidity appends the constructor arguments to the init code, and the contract code it returns usually ends with metadata about the compiler. Neither are instructions, so they disassemble into nonsense."
+		if rlpTok == nil && body[0] == 0x80 {
+			value = "No Code"
+		} else {
+			value = fmt.Sprintf("%d bytes", len(body))
+			children = shiftTokens(initCodeTokens(body), prefixLen)
 		}
 	case SIG_V:
 		title = "Signature V"
 		desc = "Indicates both the chainID of the transaction and the parity (odd or even) of the y component of the public key."
-		longDesc = ""
-		value = "0x" + hex.EncodeToString(body)
+		chainID, parity, ok := decodeV(num)
+		switch {
+		case !ok:
+			longDesc = "This isn't a valid v. It should be 27 or 28 before EIP-155, or chainId * 2 + 35 or 36 after it."
+			value = fmt.Sprintf("%s (0x%x)", num.String(), body)
+		case chainID == nil:
+			longDesc = fmt.Sprintf("This is a pre-EIP-155 signature: v is 27 + the y parity (%s - 27 = %d).\nIt doesn't commit to a chain ID, so the same transaction could be replayed on any chain.", num.String(), parity)
+			value = fmt.Sprintf("%s: pre-EIP-155, y parity %d", num.String(), parity)
+		default:
+			longDesc = fmt.Sprintf("Since EIP-155 v is chainId * 2 + 35 + the y parity, which binds the signature to one chain.\nChain ID: (%s - 35) / 2 = %s\nY parity: (%s - 35) %% 2 = %d", num.String(), chainID.String(), num.String(), parity)
+			value = fmt.Sprintf("%s: chain ID %s, y parity %d", num.String(), describeChainID(chainID), parity)
+		}
 	case SIG_R:
 		title = "Signature R"
 		desc = "(r) part of the signature pair (r,s)."
@@ -218,14 +605,98 @@ func genToken(val interface{}, f EthField) []token {
 		desc = "(s) part of the signature pair (r,s)."
 		longDesc = "Generated using the ECDSA signing algorithm."
 		value = "0x" + hex.EncodeToString(body)
+	case CHAIN_ID:
+		title = "Chain ID"
+		desc = "The ID of the chain this transaction is valid on (1 is Ethereum mainnet)."
+		longDesc = "Signing over the chain ID means the transaction can't be replayed on another chain."
+		value = describeChainID(num)
+	case ACCESS_LIST:
+		title = "Access List"
+		desc = "The accounts and storage slots this transaction declares it will access."
+		var addresses, keys int
+		children, addresses, keys = accessListTokens(enc)
+		gas := addresses*accessListAddressGas + keys*accessListStorageKeyGas
+		value = fmt.Sprintf("%d addresses, %d storage keys (+%d gas)", addresses, keys, gas)
+		longDesc = fmt.Sprintf("Each address costs %d gas and each storage key %d gas, charged up front as part of the intrinsic gas like the 21000 every transaction pays.\n"+
+			"This list adds %d x %d + %d x %d = %d gas. Accessing them later in the transaction is cheaper because they were paid for up front.",
+			accessListAddressGas, accessListStorageKeyGas, addresses, accessListAddressGas, keys, accessListStorageKeyGas, gas)
+	case Y_PARITY:
+		title = "Signature Y Parity"
+		desc = "The parity (odd or even) of the y coordinate of the signature's curve point, 0 or 1."
+		longDesc = "Together with r and s it lets anyone recover the sender's public key. Unlike the legacy v it doesn't include the chain ID, which is its own field."
+		value = num.String()
+	case MAX_FEE_PER_BLOB_GAS:
+		title = "Max Fee Per Blob Gas"
+		desc = "The most the sender will pay per unit of blob gas (in wei). Each blob uses 131072 blob gas."
+		longDesc = "Blob gas has its own base fee that rises and falls with how many blobs blocks carry, separately from the normal base fee. All of it is burned."
+		value = fmt.Sprintf("%s (%s)", num.String(), formatGwei(num))
+	case BLOB_VERSIONED_HASHES:
+		title = "Blob Versioned Hashes"
+		desc = "One hash for each blob carried with this transaction."
+		longDesc = "Each one is a version byte followed by 31 bytes of the sha256 hash of the blob's KZG commitment."
+		children = versionedHashTokens(enc)
+		value = fmt.Sprintf("%d blobs", len(children)/2)
+	case AUTHORIZATION_LIST:
+		title = "Authorization List"
+		desc = "Signed authorizations that set the code of ordinary accounts to delegate to a contract."
+		children = authorizationTokens(enc, opts)
+		value = fmt.Sprintf("%d authorizations (+%d gas)", len(children), len(children)*authorizationGas)
+		longDesc = fmt.Sprintf("Each authorization costs %d gas up front, part of which is refunded if the account already existed.\nThey are applied before the transaction runs, in order. One that is invalid, for example because its nonce is stale, is skipped rather than failing the transaction.", authorizationGas)
+	case AUTH_CHAIN_ID:
+		title = "Authorization Chain ID"
+		desc = "The chain this authorization is valid on."
+		value = describeChainID(num)
+		if num.Sign() == 0 {
+			longDesc = "0 means the authorization is valid on every chain, so the same signature can set up the account everywhere."
+			value = "0 (any chain)"
+		}
+	case AUTH_ADDRESS:
+		title = "Delegate Address"
+		desc = "The contract whose code the signing account will run. Calls to the account execute this code with the account's own balance and storage."
+		longDesc = "The zero address clears an earlier delegation instead."
+		value = "0x" + hex.EncodeToString(body)
+	case AUTH_NONCE:
+		title = "Authorization Nonce"
+		desc = "Has to match the signing account's nonce when the authorization is applied, which then goes up by one. This stops the authorization from being replayed."
+		longDesc = "If the signing account also sends the transaction its nonce has already gone up by one by then, so the authorization needs the nonce after the transaction's."
+		value = fmt.Sprintf("%s (0x%x)", num.String(), body)
+	case EIP155_ZERO:
+		title = "EIP-155 Zero"
+		desc = "Stands in for r or s, which don't exist until the transaction is signed."
+		longDesc = "EIP-155 signs over the chain ID and two zeros so legacy transactions can be tied to one chain without adding a field to them."
+		value = num.String()
+	case MAX_PRIORITY_FEE_PER_GAS:
+		title = "Max Priority Fee Per Gas"
+		desc = "The most the sender will pay per unit of gas (in wei) as a tip to the block producer, on top of the base fee."
+		longDesc = "The tip is what makes it worth including the transaction. It is cut down if the base fee plus the tip would go over the max fee per gas."
+		value = fmt.Sprintf("%s (%s)", num.String(), formatGwei(num))
+	case MAX_FEE_PER_GAS:
+		title = "Max Fee Per Gas"
+		desc = "The most the sender will pay per unit of gas (in wei), base fee and tip together."
+		longDesc = "The transaction can't be included in a block whose base fee is higher than this. Anything left between the max fee and what is actually paid stays with the sender."
+		value = fmt.Sprintf("%s (%s)", num.String(), formatGwei(num))
+	}
+	// signatures are checked as they are explained
+	var warnings []token
+	if f.isSignature() {
+		var note string
+		note, warnings = checkSignatureField(f, num, body)
+		if note != "" {
+			longDesc = strings.TrimPrefix(longDesc+"\n"+note, "\n")
+		}
 	}
+
 	toks = append(toks, token{
 		Token:       hex.EncodeToString(body),
 		Description: desc,
 		FlavorText:  longDesc,
 		Value:       value,
 		Title:       title,
+		Offset:      prefixLen,
+		Length:      len(body),
+		Children:    children,
 	})
+	toks = append(toks, shiftTokens(warnings, prefixLen)...)
 
 	return toks
 }
@@ -251,6 +722,7 @@ func addRLPToken(enc []byte) (*token, int) {
 			Title:       "RLP Length Prefix",
 			Description: fmt.Sprintf("RLP Length Prefix. The next field is an RLP 'string' of length %d (0x%x - 0x80).", int(prefix)-0x80, prefix),
 			Value:       "0x" + hex.EncodeToString([]byte{prefix}),
+			Length:      1,
 		}
 		return tok, len(enc) - (int(prefix) - 0x80)
 	// rlp "string" with length > 55 bytes
@@ -264,10 +736,32 @@ func addRLPToken(enc []byte) (*token, int) {
 			Title:       "RLP Length Prefix",
 			Description: fmt.Sprintf("This is an RLP 'string' with length > 55 bytes.\nThe first byte (0x%x-0xB7) tells us the length of the length (%d bytes).\nThe actual field length is %s bytes (0x%x).", prefix, l, bytesToInt(fieldLen).String(), fieldLen),
 			Value:       "0x" + hex.EncodeToString(enc[:1+l]),
+			Length:      1 + int(l),
 		}
 		return tok, 1 + len(fieldLen)
+	// rlp "list" with total length 0-55 bytes
+	case prefix < 0xF8:
+		tok := &token{
+			Token:       hex.EncodeToString([]byte{prefix}),
+			Title:       "RLP List Prefix",
+			Description: fmt.Sprintf("RLP is an encoding/decoding algorithm that helps Ethereum to serialize data.\nThis is an RLP 'list' whose items take up %d bytes (0x%x - 0xC0).", int(prefix)-0xC0, prefix),
+			Value:       "0x" + hex.EncodeToString([]byte{prefix}),
+			Length:      1,
+		}
+		return tok, 1
+	// rlp "list" with total length > 55 bytes
 	default:
-		panic("RLP prefix outside of expected range for ETH tx")
+		l := prefix - 0xF7
+		listLen := enc[1 : 1+l]
+
+		tok := &token{
+			Token:       hex.EncodeToString(enc[:1+l]),
+			Title:       "RLP Prefix",
+			Description: fmt.Sprintf("RLP is an encoding/decoding algorithm that helps Ethereum to serialize data.\nThis is an RLP 'list' with total length > 55 bytes.\nThe first byte (0x%x - 0xF7) tells us the length of the length (%d bytes).\nThe actual length of the list in bytes is %s bytes (0x%x).", prefix, l, bytesToInt(listLen), listLen),
+			Value:       "0x" + hex.EncodeToString(enc[:1+l]),
+			Length:      1 + int(l),
+		}
+		return tok, 1 + len(listLen)
 	}
 
 }
@@ -0,0 +1,285 @@
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// A log is what the LOG0 to LOG4 opcodes leave behind: the address of the contract
+// that emitted it, up to 4 topics and any amount of data. Logs aren't bytes on their own,
+// so token offsets count through the address, the topics and the data one after another.
+
+const maxTopics = 4
+
+// eventLog is a log as eth_getLogs and transaction receipts return it
+type eventLog struct {
+	Address string   `json:"address"`
+	Topics  []string `json:"topics"`
+	Data    string   `json:"data"`
+}
+
+// eventCandidate is an event that a log might be
+type eventCandidate struct {
+	sig     string
+	args    []abiArg
+	indexed []bool
+	// where we know the event from
+	source string
+}
+
+// parseEventDeclaration parses an event written like in solidity, such as
+// Transfer(address indexed from, address indexed to, uint256 value)
+func parseEventDeclaration(decl string) (eventCandidate, error) {
+	e := eventCandidate{source: "our signature database"}
+	open := strings.Index(decl, "(")
+	if open < 1 || !strings.HasSuffix(decl, ")") {
+		return e, fmt.Errorf("%q is not an event like Name(type indexed name, type name)", decl)
+	}
+	params, err := splitABITypes(decl[open+1 : len(decl)-1])
+	if err != nil {
+		return e, err
+	}
+
+	types := make([]string, len(params))
+	for i, p := range params {
+		words := strings.Fields(p)
+		t, err := parseABIType(words[0])
+		if err != nil {
+			return e, err
+		}
+		arg, indexed := abiArg{typ: t}, false
+		for _, w := range words[1:] {
+			if w == "indexed" {
+				indexed = true
+			} else {
+				arg.name = w
+			}
+		}
+		e.args = append(e.args, arg)
+		e.indexed = append(e.indexed, indexed)
+		types[i] = t.String()
+	}
+	e.sig = fmt.Sprintf("%s(%s)", decl[:open], strings.Join(types, ","))
+	return e, nil
+}
+
+// eventCandidates are the events whose signature hashes to topic, from the contract
+// ABI first if there is one and then from our signature database
+func eventCandidates(topic []byte, contract *abi.ABI) []eventCandidate {
+	var candidates []eventCandidate
+	if contract != nil {
+		if event, err := contract.EventByID(common.BytesToHash(topic)); err == nil && !event.Anonymous {
+			e := eventCandidate{sig: event.Sig(), source: "the contract ABI"}
+			for _, in := range event.Inputs {
+				e.args = append(e.args, abiArg{name: in.Name, typ: in.Type})
+				e.indexed = append(e.indexed, in.Indexed)
+			}
+			candidates = append(candidates, e)
+		}
+	}
+	for _, decl := range eventSignatures[hex.EncodeToString(topic)] {
+		if e, err := parseEventDeclaration(decl); err == nil {
+			candidates = append(candidates, e)
+		}
+	}
+	return candidates
+}
+
+// eventLogParser explains a log emitted by a contract, given as JSON
+type eventLogParser struct{}
+
+func init() {
+	registerParser(parserInfo{
+		Name:        "log",
+		Type:        "Event Log",
+		Priority:    250,
+		Description: "An event log as JSON, like {\"address\": ..., \"topics\": [...], \"data\": ...} from eth_getLogs or a receipt. Events are looked up in the signature database or the contract ABI.",
+	}, &eventLogParser{})
+}
+
+func (l *eventLogParser) understands(s string) bool {
+	var entry eventLog
+	return strings.HasPrefix(strings.TrimSpace(s), "{") && json.Unmarshal([]byte(s), &entry) == nil && entry.Topics != nil
+}
+
+func (l *eventLogParser) confidence(s string) float64 {
+	return 0.95
+}
+
+func (l *eventLogParser) parse(s string, opts options) ([]token, error) {
+	var entry eventLog
+	if err := json.Unmarshal([]byte(s), &entry); err != nil {
+		return nil, newParseError(errInvalidInput, -1, "A log should be a JSON object like {\"address\": \"0x...\", \"topics\": [\"0x...\"], \"data\": \"0x...\"}: %v", err)
+	}
+
+	address, err := decodeHex(entry.Address)
+	if err != nil || len(address) != common.AddressLength {
+		return nil, newParseError(errInvalidInput, -1, "The address should be 20 bytes of hex but it is %q.", entry.Address)
+	}
+	if len(entry.Topics) > maxTopics {
+		return nil, newParseError(errInvalidInput, -1, "A log has at most %d topics, one for each of the LOG0 to LOG4 opcodes, but this has %d.", maxTopics, len(entry.Topics))
+	}
+	topics := make([][]byte, len(entry.Topics))
+	for i, t := range entry.Topics {
+		if topics[i], err = decodeHex(t); err != nil || len(topics[i]) != 32 {
+			return nil, newParseError(errInvalidInput, -1, "Topic %d should be 32 bytes of hex but it is %q.", i, t)
+		}
+	}
+	data, err := decodeHex(entry.Data)
+	if err != nil {
+		return nil, newParseError(errInvalidHex, -1, "The data should be hex: %v", err)
+	}
+
+	all := append([]byte{}, address...)
+	for _, t := range topics {
+		all = append(all, t...)
+	}
+	dataOff := len(all)
+	all = append(all, data...)
+
+	root := token{
+		Token:       abbreviateHex(all),
+		Title:       "Event Log",
+		Description: fmt.Sprintf("A log emitted by a contract with the LOG%d opcode. Logs are how contracts tell the outside world what happened: they are stored in the transaction receipt, not in the contract's state, and contracts can't read them back.", len(topics)),
+		FlavorText:  fmt.Sprintf("Emitting it cost 375 gas, 375 more for each of the %d topics and 8 per byte of the %d bytes of data, plus the memory the data was in.", len(topics), len(data)),
+		Length:      len(all),
+	}
+	addrTok := token{
+		Token:       hex.EncodeToString(address),
+		Title:       "Address",
+		Description: "The contract that emitted the log. It is filled in by the EVM, so a contract can't emit a log pretending to be another one.",
+		Value:       common.BytesToAddress(address).Hex(),
+		Length:      len(address),
+	}
+
+	if len(topics) == 0 {
+		root.Value = "Anonymous log"
+		root.Children = []token{addrTok, logDataToken(data, nil, dataOff)}
+		root.Description += "\nWithout topics there is no event signature. Solidity only emits logs like this for anonymous events without indexed arguments."
+		return []token{root}, nil
+	}
+
+	root.Value, root.Children = eventTokens(topics, data, eventCandidates(topics[0], opts.contract))
+	root.Children = append([]token{addrTok}, shiftTokens(root.Children, len(address))...)
+	return []token{root}, nil
+}
+
+// eventTokens explains the topics and data of a log as the first of candidates whose
+// indexed arguments match the topics and whose other arguments decode from the data.
+// It returns a short summary and the tokens, with offsets relative to the first topic.
+func eventTokens(topics [][]byte, data []byte, candidates []eventCandidate) (string, []token) {
+	dataOff := 32 * len(topics)
+	sigTok := token{
+		Token:       hex.EncodeToString(topics[0]),
+		Title:       "Event Signature",
+		Description: "The first topic is the keccak256 hash of the event's signature, like Transfer(address,address,uint256), which is how an event is identified. Which arguments are indexed isn't part of it.",
+		Value:       "0x" + hex.EncodeToString(topics[0]),
+		Length:      32,
+	}
+
+	var failures []string
+	for _, e := range candidates {
+		var indexed, unindexed []abiArg
+		for i, arg := range e.args {
+			if arg.name == "" {
+				arg.name = fmt.Sprintf("Argument %d", i)
+			}
+			if e.indexed[i] {
+				indexed = append(indexed, arg)
+			} else {
+				unindexed = append(unindexed, arg)
+			}
+		}
+		if len(indexed) != len(topics)-1 {
+			failures = append(failures, fmt.Sprintf("%s has %d indexed arguments but the log has %d topics after the signature", e.sig, len(indexed), len(topics)-1))
+			continue
+		}
+		d := &abiDecoder{data: data}
+		dataToks, perr := d.tuple(unindexed, 0, "the data")
+		if perr != nil {
+			failures = append(failures, fmt.Sprintf("%s: %s", e.sig, perr.Error()))
+			continue
+		}
+
+		sigTok.Value = e.sig
+		sigTok.FlavorText = fmt.Sprintf("This is the hash of %s in %s.", e.sig, e.source)
+		if len(candidates) > 1 {
+			sigTok.FlavorText += fmt.Sprintf(" %d events have this signature, this is the first whose indexed arguments match the topics.", len(candidates))
+		}
+		toks := []token{sigTok}
+		for i, arg := range indexed {
+			toks = append(toks, shiftTokens([]token{topicToken(topics[i+1], arg)}, 32*(i+1))...)
+		}
+		dataToks = append(dataToks, d.unusedTokens("the arguments")...)
+		toks = append(toks, logDataToken(data, dataToks, dataOff))
+		return e.sig, toks
+	}
+
+	sigTok.FlavorText = "This isn't the signature of an event we know, so the topics and data can only be shown as 32 byte words."
+	if len(failures) > 0 {
+		sigTok.FlavorText = fmt.Sprintf("The log doesn't match any event with this signature. %s.", strings.Join(failures, "; "))
+	}
+	toks := []token{sigTok}
+	for i, t := range topics[1:] {
+		toks = append(toks, token{
+			Token:       hex.EncodeToString(t),
+			Title:       fmt.Sprintf("Topic %d", i+1),
+			Description: "An indexed argument of the event.",
+			FlavorText:  guessWord(t),
+			Value:       "0x" + hex.EncodeToString(t),
+			Offset:      32 * (i + 1),
+			Length:      32,
+		})
+	}
+	return "0x" + hex.EncodeToString(topics[0]) + " (unknown event)", append(toks, logDataToken(data, nil, dataOff))
+}
+
+// topicToken explains an indexed argument stored in topic. Offsets are relative to topic.
+func topicToken(topic []byte, arg abiArg) token {
+	tok := token{
+		Token:      hex.EncodeToString(topic),
+		Title:      fmt.Sprintf("%s (%s, indexed)", arg.name, arg.typ.String()),
+		FlavorText: "Indexed arguments are stored as topics, which go into the block's bloom filter so eth_getLogs can find logs by them without reading every log. A log has at most 4 topics and the event signature takes the first, so at most 3 arguments can be indexed.",
+		Length:     32,
+	}
+	if isDynamic(arg.typ) || arg.typ.T == abi.ArrayTy || arg.typ.T == abi.TupleTy {
+		tok.Value = "0x" + hex.EncodeToString(topic)
+		tok.Description = fmt.Sprintf("A %s doesn't fit in a topic, so the topic holds a keccak256 hash of it instead. Logs can be found by the value, but the value itself can't be read back from the log.", arg.typ.String())
+		return tok
+	}
+	value, desc, err := describeABIWord(arg.typ, topic)
+	if err != nil {
+		tok.Value = "0x" + hex.EncodeToString(topic)
+		tok.Description = fmt.Sprintf("Should be a %s but isn't: %v.", arg.typ.String(), err)
+		return tok
+	}
+	tok.Value = value
+	tok.Description = desc + " Indexed values that fit in a word are stored in the topic as they are."
+	return tok
+}
+
+// logDataToken explains the data of a log at dataOff, with the tokens of the arguments
+// decoded from it relative to data. Without them the data is split into words.
+func logDataToken(data []byte, args []token, dataOff int) token {
+	if args == nil {
+		args = wordTokens(data)
+	}
+	value := fmt.Sprintf("%d bytes", len(data))
+	if len(data) == 0 {
+		value = "No Data"
+	}
+	return token{
+		Token:       abbreviateHex(data),
+		Title:       "Data",
+		Description: "The arguments that aren't indexed, ABI encoded. Data costs 8 gas per byte against 375 for a topic, but logs can't be searched by it.",
+		Value:       value,
+		Offset:      dataOff,
+		Length:      len(data),
+		Children:    shiftTokens(args, dataOff),
+	}
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+var (
+	gwei  = big.NewInt(1e9)
+	ether = big.NewInt(1e18)
+)
+
+// parseWei reads an amount of wei given as decimal, 0x hex, or a decimal amount of gwei like "1.5 gwei"
+func parseWei(s string) (*big.Int, error) {
+	s = strings.TrimSpace(strings.ToLower(s))
+
+	if strings.HasSuffix(s, "gwei") {
+		amount, ok := new(big.Rat).SetString(strings.TrimSpace(strings.TrimSuffix(s, "gwei")))
+		if !ok || amount.Sign() < 0 {
+			return nil, fmt.Errorf("invalid gwei amount %q", s)
+		}
+		amount.Mul(amount, new(big.Rat).SetInt(gwei))
+		if !amount.IsInt() {
+			return nil, fmt.Errorf("%q is a fraction of a wei", s)
+		}
+		return amount.Num(), nil
+	}
+
+	wei, ok := new(big.Int).SetString(s, 10)
+	if strings.HasPrefix(s, "0x") {
+		wei, ok = new(big.Int).SetString(s[2:], 16)
+	}
+	if !ok || wei.Sign() < 0 {
+		return nil, fmt.Errorf("invalid wei amount %q", s)
+	}
+	return wei, nil
+}
+
+// formatUnit prints wei in a bigger unit, without trailing zeros
+func formatUnit(wei, unit *big.Int, name string) string {
+	amount := new(big.Rat).SetFrac(wei, unit).FloatString(18)
+	amount = strings.TrimRight(strings.TrimRight(amount, "0"), ".")
+	return amount + " " + name
+}
+
+func formatGwei(wei *big.Int) string {
+	return formatUnit(wei, gwei, "gwei")
+}
+
+func formatEther(wei *big.Int) string {
+	return formatUnit(wei, ether, "ETH")
+}
+
+// feeTokens explains what the sender pays per unit of gas. With a base fee it
+// also splits that into the part that is burned and the tip the block producer keeps.
+func feeTokens(fields txFields, baseFee *big.Int) []token {
+	gasLimit := fields.int(GAS_LIMIT)
+
+	// legacy and EIP-2930 transactions name a single price
+	if _, ok := fields[MAX_FEE_PER_GAS]; !ok {
+		if _, ok := fields[GAS_PRICE]; !ok || baseFee == nil {
+			return nil
+		}
+		gasPrice := fields.int(GAS_PRICE)
+		tok := token{
+			Title:       "Effective Gas Price",
+			Description: "Since EIP-1559 every block has a base fee that is burned. A transaction with a single gas price pays all of it per unit of gas, and whatever is left over after the base fee is the tip for the block producer.",
+			Kind:        kindDerived,
+		}
+		if gasPrice.Cmp(baseFee) < 0 {
+			tok.Value = fmt.Sprintf("Not includable: the gas price of %s is below the base fee of %s", formatGwei(gasPrice), formatGwei(baseFee))
+			return []token{tok}
+		}
+		tip := new(big.Int).Sub(gasPrice, baseFee)
+		tok.Value = fmt.Sprintf("%s per gas (%s burned + %s tip)", formatGwei(gasPrice), formatGwei(baseFee), formatGwei(tip))
+		tok.FlavorText = burnedAndTip(baseFee, tip, gasLimit)
+		return []token{tok}
+	}
+
+	maxFee := fields.int(MAX_FEE_PER_GAS)
+	maxPriority := fields.int(MAX_PRIORITY_FEE_PER_GAS)
+	tok := token{
+		Title:       "Effective Gas Price",
+		Description: "An EIP-1559 transaction pays the block's base fee, which is burned, plus a tip for the block producer.\nThe effective price per gas is min(Max Fee Per Gas, Base Fee + Max Priority Fee Per Gas), so the sender never pays more than the max fee and the tip never exceeds the max priority fee.",
+		Kind:        kindDerived,
+	}
+	if baseFee == nil {
+		tok.Value = fmt.Sprintf("At most %s per gas, depending on the block's base fee", formatGwei(maxFee))
+		tok.FlavorText = "Pass the base fee of a block to see what this transaction would pay in it."
+		return []token{tok}
+	}
+	if maxFee.Cmp(baseFee) < 0 {
+		tok.Value = fmt.Sprintf("Not includable: the max fee of %s is below the base fee of %s", formatGwei(maxFee), formatGwei(baseFee))
+		return []token{tok}
+	}
+
+	tip := new(big.Int).Sub(maxFee, baseFee)
+	if tip.Cmp(maxPriority) > 0 {
+		tip = maxPriority
+	}
+	effective := new(big.Int).Add(baseFee, tip)
+	tok.Value = fmt.Sprintf("%s per gas (%s burned + %s tip)", formatGwei(effective), formatGwei(baseFee), formatGwei(tip))
+	tok.FlavorText = burnedAndTip(baseFee, tip, gasLimit)
+	return []token{tok}
+}
+
+// the totals if the transaction used all of its gas
+func burnedAndTip(baseFee, tip, gasLimit *big.Int) string {
+	burned := new(big.Int).Mul(baseFee, gasLimit)
+	tips := new(big.Int).Mul(tip, gasLimit)
+	return fmt.Sprintf("If it uses its whole gas limit of %s, %s is burned and the block producer gets a %s tip. Unused gas isn't paid for.", gasLimit.String(), formatEther(burned), formatEther(tips))
+}
+
+// blobGasTokens explains what the blobs of an EIP-4844 transaction can cost at most
+func blobGasTokens(fields txFields) []token {
+	if _, ok := fields[BLOB_VERSIONED_HASHES]; !ok {
+		return nil
+	}
+	hashes, _ := splitRLPItems(fields.content(BLOB_VERSIONED_HASHES), 0)
+	blobGas := big.NewInt(int64(len(hashes) * blobGasPerBlob))
+	maxCost := new(big.Int).Mul(blobGas, fields.int(MAX_FEE_PER_BLOB_GAS))
+	return []token{{
+		Title:       "Blob Gas",
+		Description: fmt.Sprintf("Blobs are paid for with blob gas, %d for each blob, on top of the transaction's normal gas. Blob gas has its own base fee and all of it is burned.", blobGasPerBlob),
+		FlavorText:  "The blob base fee goes up when blocks carry more than the target number of blobs and down when they carry fewer.",
+		Value:       fmt.Sprintf("%d blobs use %s blob gas, costing at most %s", len(hashes), blobGas.String(), formatEther(maxCost)),
+		Kind:        kindDerived,
+	}}
+}
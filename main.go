@@ -7,25 +7,154 @@ import (
 	"math/big"
 	"net/http"
 	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
 )
 
+// A token explains a span of the input. Tokens that group other tokens (like an
+// RLP list and its items) carry them as Children, and their own Token covers
+// the bytes of all of their children.
+// Offset and Length locate the token in the decoded input bytes, and are what
+// to go by: Token is the hex of those bytes, but spans over 1024 bytes are cut
+// to their first 32 bytes followed by "..." (see abbreviateHex).
+// Kind is empty for ordinary tokens and flags special ones like errors. Derived
+// tokens explain something worked out from the input rather than a span of it,
+// so they have no length and come after the tokens for the input itself.
+// Warning tokens point out something suspicious about the token before them,
+// covering the same bytes, without stopping decoding like an error would.
+// Tokens that point at another part of the input, like ABI offsets, set Target
+// to the offset they point at.
 type token struct {
-	Token       string `json:"token"`
-	Title       string `json:"title"`
-	Description string `json:"description"`
-	FlavorText  string `json:"flavorText"`
-	Value       string `json:"value"`
-	Type        string `json:"type"`
+	Token       string  `json:"token"`
+	Title       string  `json:"title"`
+	Description string  `json:"description"`
+	FlavorText  string  `json:"flavorText"`
+	Value       string  `json:"value"`
+	Type        string  `json:"type"`
+	Kind        string  `json:"kind,omitempty"`
+	Offset      int     `json:"offset"`
+	Length      int     `json:"length"`
+	Target      *int    `json:"target,omitempty"`
+	Children    []token `json:"children,omitempty"`
+}
+
+// shiftTokens moves toks and all of their descendants by n bytes. Helpers build
+// tokens relative to the slice they were given and callers shift them into place.
+func shiftTokens(toks []token, n int) []token {
+	for i := range toks {
+		toks[i].Offset += n
+		if toks[i].Target != nil {
+			target := *toks[i].Target + n
+			toks[i].Target = &target
+		}
+		shiftTokens(toks[i].Children, n)
+	}
+	return toks
+}
+
+// token kinds
+const (
+	kindError   = "error"
+	kindDerived = "derived"
+	kindWarning = "warning"
+)
+
+// setType sets the type on toks and all of their descendants
+func setType(toks []token, typ string) {
+	for i := range toks {
+		toks[i].Type = typ
+		setType(toks[i].Children, typ)
+	}
 }
 
 type request struct {
-	Input string `json:"input"`
-	Hint  string `json:"hint"`
+	Input   string          `json:"input"`
+	Hint    string          `json:"hint"`
+	BaseFee string          `json:"baseFee"`
+	ABI     json.RawMessage `json:"abi"`
+	Types   string          `json:"types"`
+}
+
+// options are the optional parts of a request that refine how an input is explained
+type options struct {
+	// block base fee in wei, nil if not given
+	baseFee *big.Int
+	// contract ABI for decoding calldata, nil if not given
+	contract *abi.ABI
+	// custom errors from the contract ABI, which the abi package doesn't read
+	contractErrors []callCandidate
+	// types of ABI encoded data, nil if not given
+	types []abiArg
+}
+
+// options validates and converts the optional request fields
+func (req request) options() (options, *parseError) {
+	var opts options
+	if req.BaseFee != "" {
+		fee, err := parseWei(req.BaseFee)
+		if err != nil {
+			return opts, newParseError(errBadRequest, -1, "baseFee %q should be an amount of wei like 1500000000, 0x59682f00 or 1.5 gwei", req.BaseFee)
+		}
+		opts.baseFee = fee
+	}
+	if len(req.ABI) > 0 {
+		contract, err := parseContractABI(req.ABI)
+		if err != nil {
+			return opts, newParseError(errBadRequest, -1, "abi should be a contract ABI, the JSON array solc and block explorers give out: %v", err)
+		}
+		opts.contract = contract
+		// the abi package skips errors, so their types still need checking
+		if opts.contractErrors, err = parseContractErrors(req.ABI); err != nil {
+			return opts, newParseError(errBadRequest, -1, "abi should be a contract ABI, the JSON array solc and block explorers give out: %v", err)
+		}
+	}
+	if req.Types != "" {
+		types, err := parseArgumentTypes(req.Types)
+		if err != nil {
+			return opts, newParseError(errBadRequest, -1, "types should list ABI types like (uint256,bytes,address[]): %v", err)
+		}
+		opts.types = types
+	}
+	return opts, nil
 }
 
 type parser interface {
 	understands(string) bool
-	parse(string) ([]token, error)
+	parse(string, options) ([]token, error)
+}
+
+// parsers can optionally report how likely it is that an input they understand
+// really is their format. Parsers that don't implement it get defaultConfidence.
+type confidencer interface {
+	confidence(string) float64
+}
+
+const defaultConfidence = 0.5
+
+// parsers whose format can depend on the request, like custom errors declared in
+// its ABI, can also recognise inputs with the options. Anything they understand
+// without the options they still have to understand in understands.
+type optionsUnderstander interface {
+	understandsWith(string, options) bool
+}
+
+// interpretation is one parser's explanation of the input. If the parser only
+// got part way through, Error says why and the tokens end with an error token.
+type interpretation struct {
+	Parser     string      `json:"parser"`
+	Type       string      `json:"type"`
+	Confidence float64     `json:"confidence"`
+	Tokens     []token     `json:"tokens"`
+	Error      *parseError `json:"error,omitempty"`
+}
+
+type response struct {
+	Input   string           `json:"input"`
+	Results []interpretation `json:"results"`
 }
 
 // test xpub (xpub6CUGRUonZSQ4TWtTMmzXdrXDtypWKiKrhko4egpiMZbpiaQL2jkwSB1icqYh2cfDfVxdx4df189oLKnC5fSwqPfgyP3hooxujYzAu3fDVmz)
@@ -33,25 +162,78 @@ type parser interface {
 
 func main() {
 
+	if err := configureTxSource(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	if len(os.Args) > 1 {
+		var err error
+		switch os.Args[1] {
+		case "explain":
+			err = runExplain(os.Args[2:], os.Stdin, os.Stdout)
+		case "opgen":
+			err = runOpgen(os.Args[2:], os.Stdout)
+		case "serve":
+			serve()
+		default:
+			fmt.Fprintf(os.Stderr, "unknown command %q\n\n", os.Args[1])
+			fmt.Fprintf(os.Stderr, usage, filepath.Base(os.Args[0]))
+			os.Exit(2)
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	serve()
+}
+
+func serve() {
+
 	port := os.Getenv("PORT")
 
 	if port == "" {
 		port = "8080"
 	}
 
+	if n, err := strconv.Atoi(os.Getenv("BATCH_CONCURRENCY")); err == nil && n > 0 {
+		batchConcurrency = n
+	}
+
 	fmt.Println("start")
 	http.HandleFunc("/", http.HandlerFunc(handleData))
+	http.HandleFunc("/batch", http.HandlerFunc(handleBatch))
+	http.HandleFunc("/parsers", http.HandlerFunc(handleParsers))
 	http.ListenAndServe(":"+port, nil)
 	fmt.Println("end")
-
-	// generate opcode list
-	//runner()
 }
 
-func handleData(w http.ResponseWriter, r *http.Request) {
+func allowCORS(w http.ResponseWriter) {
 	w.Header().Set("Access-Control-Allow-Origin", "*")
 	w.Header().Set("Access-Control-Allow-Headers", "Accept, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization")
 	w.Header().Set("Access-Control-Allow-Methods", "POST, GET, OPTIONS, PUT, DELETE")
+}
+
+// list the registered parsers in the order they are tried
+func handleParsers(w http.ResponseWriter, r *http.Request) {
+	allowCORS(w)
+	if r.Method == "OPTIONS" {
+		return
+	}
+
+	res, err := json.MarshalIndent(parserInfos(), "", "	")
+	if err != nil {
+		panic(err)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(res)
+}
+
+func handleData(w http.ResponseWriter, r *http.Request) {
+	allowCORS(w)
 	if r.Method == "OPTIONS" {
 		return
 	}
@@ -60,49 +242,120 @@ func handleData(w http.ResponseWriter, r *http.Request) {
 	req := request{}
 	err := dec.Decode(&req)
 	if err != nil {
-		http.Error(w, err.Error(), 500)
+		writeError(w, http.StatusBadRequest, newParseError(errBadRequest, -1, "request body is not valid JSON: %v", err))
 		return
 	}
 
-	fmt.Printf("Received: %+v\n", req)
-
-	var toks []token
+	resp, perr := explain(req)
+	if perr != nil {
+		status := http.StatusUnprocessableEntity
+		if perr.Code == errUnknownParser || perr.Code == errBadRequest {
+			status = http.StatusBadRequest
+		}
+		writeError(w, status, perr)
+		return
+	}
 
-	eth := ethTxParser{}
-	xpub := xpubParser{}
-	op := opcodeParser{}
+	res, err := json.MarshalIndent(resp, "", "	")
+	if err != nil {
+		panic(err)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(res)
+}
 
-	var typ string
-	switch {
+// explain runs every parser that understands the input and returns their
+// interpretations, most confident first. It fails if no parser understands the
+// input, or if every parser that claimed the input failed to parse it.
+// If the request hints at a parser by name, only that parser is used and auto-detection is skipped.
+func explain(req request) (response, *parseError) {
+	resp := response{Input: req.Input, Results: []interpretation{}}
 
-	case eth.understands(req.Input):
-		toks, err = eth.parse(req.Input)
-		typ = "Eth Transaction"
-	case xpub.understands(req.Input):
-		toks, err = xpub.parse(req.Input)
-		typ = "XPUB (Base58 decoded)"
-	case op.understands(req.Input):
-		toks, err = op.parse(req.Input)
-		typ = "EVM Opcodes"
-	default:
-		w.Write([]byte("Sorry, I down Understand this format"))
-		return
+	opts, perr := req.options()
+	if perr != nil {
+		return resp, perr
 	}
 
-	if err != nil {
-		log.Fatalf("Parse error: %v\n", err)
+	if req.Hint != "" {
+		p, ok := lookupParser(req.Hint)
+		if !ok {
+			return resp, newParseError(errUnknownParser, -1, "unknown parser hint %q, expected one of: %s", req.Hint, strings.Join(parserNames(), ", "))
+		}
+		toks, err := p.parse(req.Input, opts)
+		if err != nil && len(toks) == 0 {
+			perr := toParseError(err, errInvalidInput)
+			perr.Parser = p.Name
+			perr.Message = fmt.Sprintf("the %s parser could not parse this input: %s", p.Name, perr.Message)
+			return resp, perr
+		}
+		setType(toks, p.Type)
+		interp := interpretation{
+			Parser:     p.Name,
+			Type:       p.Type,
+			Confidence: 1,
+			Tokens:     toks,
+		}
+		if err != nil {
+			interp.Error = toParseError(err, errInvalidInput)
+			interp.Error.Parser = p.Name
+		}
+		resp.Results = append(resp.Results, interp)
+		return resp, nil
 	}
 
-	// Hackathon jank
-	for i := range toks {
-		toks[i].Type = typ
+	var firstErr *parseError
+	for _, p := range registry {
+		understood := p.understands(req.Input)
+		if u, ok := p.parser.(optionsUnderstander); ok {
+			understood = u.understandsWith(req.Input, opts)
+		}
+		if !understood {
+			continue
+		}
+		toks, err := p.parse(req.Input, opts)
+		if err != nil && len(toks) == 0 {
+			log.Printf("Parser %s failed: %v\n", p.Name, err)
+			if firstErr == nil {
+				firstErr = toParseError(err, errInvalidInput)
+				firstErr.Parser = p.Name
+			}
+			continue
+		}
+
+		// Hackathon jank
+		setType(toks, p.Type)
+
+		conf := defaultConfidence
+		if c, ok := p.parser.(confidencer); ok {
+			conf = c.confidence(req.Input)
+		}
+		interp := interpretation{
+			Parser:     p.Name,
+			Type:       p.Type,
+			Confidence: conf,
+			Tokens:     toks,
+		}
+		// a partial explanation is less likely to be the right one
+		if err != nil {
+			interp.Error = toParseError(err, errInvalidInput)
+			interp.Error.Parser = p.Name
+			interp.Confidence /= 2
+		}
+		resp.Results = append(resp.Results, interp)
 	}
 
-	res, err := json.MarshalIndent(toks, "", "	")
-	if err != nil {
-		panic(err)
+	if len(resp.Results) == 0 {
+		if firstErr != nil {
+			return resp, firstErr
+		}
+		return resp, newParseError(errUnknownFormat, -1, "Sorry, I don't understand this format.")
 	}
-	w.Write(res)
+
+	// registry order breaks ties
+	sort.SliceStable(resp.Results, func(i, j int) bool {
+		return resp.Results[i].Confidence > resp.Results[j].Confidence
+	})
+	return resp, nil
 }
 
 func bytesToInt(buf []byte) *big.Int {
@@ -2,29 +2,60 @@ package main
 
 import (
 	"encoding/hex"
-	"errors"
 	"fmt"
 	"strings"
 )
 
 type opcodeParser struct{}
 
+func init() {
+	registerParser(parserInfo{
+		Name:        "evm",
+		Type:        "EVM Opcodes",
+		Priority:    100,
+		Description: "EVM bytecode, disassembled into opcodes and their PUSH data.",
+	}, &opcodeParser{})
+}
+
 func (o *opcodeParser) understands(s string) bool {
 	// TODO: not foolproof
 	return strings.HasPrefix(strings.TrimPrefix(s, "0x"), "6080")
 }
 
-func (o *opcodeParser) parse(s string) ([]token, error) {
+func (o *opcodeParser) confidence(s string) float64 {
+	// 0x6080 (PUSH1 0x80) is how solidity starts almost every contract
+	return 0.8
+}
+
+func (o *opcodeParser) parse(s string, opts options) ([]token, error) {
 
-	buf, err := hex.DecodeString(strings.TrimPrefix(s, "0x"))
+	buf, err := decodeHex(s)
 	if err != nil {
-		return nil, errors.New("Not valid hex")
+		return nil, err
 	}
+	toks, perr := opcodeTokens(buf)
+	if perr != nil {
+		return toks, perr
+	}
+	return toks, nil
+}
 
+// opcodeTokens disassembles buf into opcodes and their PUSH data. If the last PUSH
+// runs past the end, the tokens end with an error token. Offsets are relative to buf.
+func opcodeTokens(buf []byte) ([]token, *parseError) {
 	var toks []token
 	var idx int
 	for idx < len(buf) {
 		var tok token
+		start := idx
+
+		// PUSH1-PUSH32 read their operand from the following bytes
+		if op := buf[idx]; op >= 0x60 && op <= 0x7f {
+			if n := int(op) - 0x5f; idx+1+n > len(buf) {
+				perr := newParseError(errTruncated, idx, "PUSH%d expects the next %d bytes to be the value it pushes but only %d remain.", n, n, len(buf)-idx-1)
+				return append(toks, errorToken(buf[idx:], perr)), perr
+			}
+		}
 
 		// Generated with opgen.go
 		switch buf[idx] {
@@ -1323,9 +1354,29 @@ func (o *opcodeParser) parse(s string) ([]token, error) {
 			}
 
 		}
+		tok.Offset = start
+		tok.Length = idx + 1 - start
 		toks = append(toks, tok)
 		idx++
 	}
 
 	return toks, nil
 }
+
+// initCodeTokens disassembles the init code of a contract creation. Init code usually
+// ends in data that isn't code, so a PUSH running past the end isn't an error.
+func initCodeTokens(code []byte) []token {
+	toks, perr := opcodeTokens(code)
+	if perr != nil {
+		rest := code[perr.Offset:]
+		toks[len(toks)-1] = token{
+			Token:       hex.EncodeToString(rest),
+			Title:       "Truncated PUSH",
+			Description: perr.Message + " The end of init code is often constructor arguments or metadata rather than instructions.",
+			Value:       "0x" + hex.EncodeToString(rest),
+			Offset:      perr.Offset,
+			Length:      len(rest),
+		}
+	}
+	return toks
+}
@@ -5,11 +5,23 @@ import (
 	"bytes"
 	"fmt"
 	"io"
+	"os"
 	"strings"
 )
 
-func runner() {
-	opgen(bytes.NewBufferString(oplist))
+// runOpgen prints the opcode switch statement for opcode.go. It reads the opcode
+// table from the file named in args, or uses the built in oplist.
+func runOpgen(args []string, out io.Writer) error {
+	var r io.Reader = bytes.NewBufferString(oplist)
+	if len(args) > 0 {
+		f, err := os.Open(args[0])
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	}
+	return opgen(r, out)
 }
 
 type opcode struct {
@@ -20,7 +32,7 @@ type opcode struct {
 }
 
 // generate a huge switch statement of EVM opcodes
-func opgen(r io.Reader) string {
+func opgen(r io.Reader, out io.Writer) error {
 
 	sc := bufio.NewScanner(r)
 	var opcodes []opcode
@@ -39,18 +51,21 @@ func opgen(r io.Reader) string {
 			gas:         strings.TrimSpace(arr[5]),
 		})
 	}
+	if err := sc.Err(); err != nil {
+		return err
+	}
 
 	for _, op := range opcodes {
-		fmt.Printf("case %s:\n", op.hex)
-		fmt.Printf("tok = token{\n")
-		fmt.Printf("\tToken: \"%s\",\n", op.hex)
-		fmt.Printf("\tTitle: \"%s\",\n", op.name)
-		fmt.Printf("\tDescription: \"%s\",\n", op.description)
-		fmt.Printf("\tValue: \"%s\",\n", op.hex)
-		fmt.Printf("}\n")
-		fmt.Println()
+		fmt.Fprintf(out, "case %s:\n", op.hex)
+		fmt.Fprintf(out, "tok = token{\n")
+		fmt.Fprintf(out, "\tToken: \"%s\",\n", op.hex)
+		fmt.Fprintf(out, "\tTitle: \"%s\",\n", op.name)
+		fmt.Fprintf(out, "\tDescription: \"%s\",\n", op.description)
+		fmt.Fprintf(out, "\tValue: \"%s\",\n", op.hex)
+		fmt.Fprintf(out, "}\n")
+		fmt.Fprintln(out)
 	}
-	return ""
+	return nil
 }
 
 var oplist = `| 0x00 | STOP | Halts execution | - | 0 |
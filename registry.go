@@ -0,0 +1,64 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// parserInfo describes a registered parser. This is what /parsers lists.
+type parserInfo struct {
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	Priority    int    `json:"priority"`
+	Description string `json:"description"`
+}
+
+type registeredParser struct {
+	parserInfo
+	parser
+}
+
+// registry holds every known parser, highest priority first
+var registry []registeredParser
+
+// registerParser makes p available to handleData. Parsers register themselves
+// from an init func so new ones can be added without touching main.go.
+// Parsers with a higher priority are asked first whether they understand the input.
+func registerParser(info parserInfo, p parser) {
+	for _, r := range registry {
+		if r.Name == info.Name {
+			panic(fmt.Sprintf("parser %q registered twice", info.Name))
+		}
+	}
+	registry = append(registry, registeredParser{parserInfo: info, parser: p})
+	sort.SliceStable(registry, func(i, j int) bool {
+		return registry[i].Priority > registry[j].Priority
+	})
+}
+
+// lookupParser finds a registered parser by name, ignoring case
+func lookupParser(name string) (registeredParser, bool) {
+	for _, r := range registry {
+		if strings.EqualFold(r.Name, strings.TrimSpace(name)) {
+			return r, true
+		}
+	}
+	return registeredParser{}, false
+}
+
+func parserNames() []string {
+	names := make([]string, 0, len(registry))
+	for _, r := range registry {
+		names = append(names, r.Name)
+	}
+	return names
+}
+
+func parserInfos() []parserInfo {
+	infos := make([]parserInfo, 0, len(registry))
+	for _, r := range registry {
+		infos = append(infos, r.parserInfo)
+	}
+	return infos
+}
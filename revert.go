@@ -0,0 +1,146 @@
+package main
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// the selectors of the errors solidity builds in
+const (
+	errorStringSelector = "08c379a0" // Error(string)
+	panicSelector       = "4e487b71" // Panic(uint256)
+)
+
+// panicCodes are the reasons solidity panics, by the code it panics with
+var panicCodes = map[uint64]string{
+	0x00: "a generic panic inserted by the compiler",
+	0x01: "an assert that failed",
+	0x11: "an arithmetic overflow or underflow outside an unchecked block",
+	0x12: "a division or modulo by zero",
+	0x21: "converting a value that is too big or negative into an enum",
+	0x22: "reading a storage byte array that is incorrectly encoded",
+	0x31: "calling pop() on an empty array",
+	0x32: "an array index that is out of bounds or negative",
+	0x41: "allocating too much memory or creating an array that is too large",
+	0x51: "calling an internal function variable that was never set",
+}
+
+// revertParser explains the data a contract reverts with, which is what a failed
+// call or transaction returns instead of its result.
+type revertParser struct{}
+
+func init() {
+	registerParser(parserInfo{
+		Name:        "revert",
+		Type:        "Revert Reason",
+		Priority:    160,
+		Description: "The data a failed call reverts with: an Error(string) message, a Panic(uint256) code or a custom error from the signature database or the contract ABI.",
+	}, &revertParser{})
+}
+
+func (r *revertParser) understands(s string) bool {
+	buf, err := hex.DecodeString(strings.TrimPrefix(s, "0x"))
+	if err != nil || len(buf) < 4 {
+		return false
+	}
+	// custom errors only the request's ABI declares are left to understandsWith
+	sel := hex.EncodeToString(buf[:4])
+	_, known := errorSignatures[sel]
+	return known || sel == errorStringSelector || sel == panicSelector
+}
+
+func (r *revertParser) understandsWith(s string, opts options) bool {
+	if r.understands(s) {
+		return true
+	}
+	buf, err := hex.DecodeString(strings.TrimPrefix(s, "0x"))
+	if err != nil || len(buf) < 4 {
+		return false
+	}
+	sel := hex.EncodeToString(buf[:4])
+	for _, e := range opts.contractErrors {
+		if selector(e.sig) == sel {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *revertParser) confidence(s string) float64 {
+	return 0.9
+}
+
+func (r *revertParser) parse(s string, opts options) ([]token, error) {
+	buf, err := decodeHex(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(buf) < 4 {
+		return nil, newParseError(errWrongLength, -1, "Revert data starts with the 4 byte selector of the error but this is only %d bytes.", len(buf))
+	}
+
+	root := token{
+		Token:       abbreviateHex(buf),
+		Title:       "Revert",
+		Description: "What a contract returns when it reverts. It is encoded like a function call: the selector of an error followed by its ABI encoded arguments.",
+		FlavorText:  "Reverting undoes everything the call changed, but the caller still gets this data back, and pays for the gas used up to that point.",
+		Length:      len(buf),
+	}
+
+	sel := hex.EncodeToString(buf[:4])
+	var candidates []callCandidate
+	switch sel {
+	case errorStringSelector:
+		candidates = []callCandidate{builtinError("Error(string)", "reason")}
+	case panicSelector:
+		candidates = []callCandidate{builtinError("Panic(uint256)", "code")}
+	default:
+		for _, e := range opts.contractErrors {
+			if selector(e.sig) == sel {
+				candidates = append(candidates, e)
+			}
+		}
+		candidates = appendKnownSignatures(candidates, buf[:4], errorSignatures)
+	}
+
+	c, toks, ok := selectorTokens(buf, token{
+		Title:       "Error Selector",
+		Description: "The first 4 bytes of the keccak256 hash of the error's signature, the same way a function selector is made.",
+	}, candidates)
+	root.Children = toks
+	switch {
+	case !ok:
+		root.Value = fmt.Sprintf("0x%s (unknown error)", sel)
+	case sel == errorStringSelector:
+		for _, t := range toks {
+			if strings.HasPrefix(t.Title, "reason ") {
+				root.Value = t.Value
+			}
+		}
+		root.Description += "\nError(string) is what require(condition, \"message\") and revert(\"message\") use."
+	case sel == panicSelector:
+		code := toks[1]
+		n := bytesToInt(buf[4:36])
+		meaning := "a code solidity doesn't use"
+		if m, known := panicCodes[n.Uint64()]; known && n.IsUint64() {
+			meaning = m
+		}
+		code.Value = fmt.Sprintf("0x%02x (%s)", n, meaning)
+		code.Description = "The reason for the panic. Solidity panics instead of reverting with a message when something that should never happen does, like " + panicCodes[0x11] + " or " + panicCodes[0x32] + "."
+		toks[1] = code
+		root.Value = fmt.Sprintf("Panic 0x%02x: %s", n, meaning)
+		root.Description += "\nPanic(uint256) means the code hit a bug rather than a check it was written to make. Before solidity 0.8.0 failed asserts used up all the gas instead, and overflows weren't checked at all."
+	default:
+		root.Value = c.sig
+		root.Description += "\nCustom errors are declared in the contract and cost less gas than messages, since only the selector and the arguments are returned."
+	}
+	return []token{root}, nil
+}
+
+// builtinError is one of the errors built into solidity, with its one argument named
+func builtinError(sig, arg string) callCandidate {
+	_, args, _ := parseSignature(sig)
+	args[0].name = arg
+	return callCandidate{sig: sig, args: args, source: "solidity itself"}
+}
@@ -0,0 +1,208 @@
+package main
+
+import (
+	"encoding/hex"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// rlpParser explains arbitrary RLP data without knowing what it represents.
+type rlpParser struct{}
+
+func init() {
+	registerParser(parserInfo{
+		Name:        "rlp",
+		Type:        "RLP",
+		Priority:    75,
+		Description: "Generic RLP encoded data, shown as a tree of lists and strings.",
+	}, &rlpParser{})
+}
+
+func (r *rlpParser) understands(s string) bool {
+	buf, err := hex.DecodeString(strings.TrimPrefix(s, "0x"))
+	if err != nil || len(buf) == 0 {
+		return false
+	}
+	// a single byte or short string is valid RLP but far too ambiguous to claim
+	if k, _, _, err := rlp.Split(buf); err != nil || k != rlp.List {
+		return false
+	}
+	return validRLP(buf, 0) == nil
+}
+
+func (r *rlpParser) confidence(s string) float64 {
+	return 0.4
+}
+
+func (r *rlpParser) parse(s string, opts options) ([]token, error) {
+	buf, err := decodeHex(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(buf) == 0 {
+		return nil, newParseError(errTruncated, 0, "no data to decode")
+	}
+	if err := validRLP(buf, 0); err != nil {
+		return nil, err
+	}
+	return rlpItemTokens(buf), nil
+}
+
+// validRLP checks that buf is exactly one well formed RLP item, recursing into lists.
+// base is the offset of buf in the input and is used to point errors at the right byte.
+func validRLP(buf []byte, base int) error {
+	k, content, rest, err := rlp.Split(buf)
+	if err != nil {
+		return rlpError(err, base)
+	}
+	if len(rest) > 0 {
+		return newParseError(errInvalidRLP, base+len(buf)-len(rest), "%d unexpected bytes after the RLP item", len(rest))
+	}
+	if k != rlp.List {
+		return nil
+	}
+	off := base + len(buf) - len(content)
+	items, err := splitRLPItems(content, off)
+	if err != nil {
+		return err
+	}
+	for _, item := range items {
+		if err := validRLP(item, off); err != nil {
+			return err
+		}
+		off += len(item)
+	}
+	return nil
+}
+
+// splitRLPItems splits the content of an RLP list into its encoded items.
+// base is the offset of content in the input.
+func splitRLPItems(content []byte, base int) ([][]byte, error) {
+	var items [][]byte
+	off := base
+	for len(content) > 0 {
+		_, _, rest, err := rlp.Split(content)
+		if err != nil {
+			return nil, rlpError(err, off)
+		}
+		item := content[:len(content)-len(rest)]
+		items = append(items, item)
+		content = rest
+		off += len(item)
+	}
+	return items, nil
+}
+
+// readRLPHeader reads the prefix of the RLP item at the start of buf. Unlike
+// rlp.Split it doesn't need the whole item to be there, so truncated input can
+// still be explained up to where it ends.
+func readRLPHeader(buf []byte) (isList bool, headerLen, contentLen int, err error) {
+	if len(buf) == 0 {
+		return false, 0, 0, io.ErrUnexpectedEOF
+	}
+
+	prefix := buf[0]
+	var sizeLen int
+	switch {
+	case prefix < 0x80:
+		return false, 0, 1, nil
+	case prefix < 0xB8:
+		return false, 1, int(prefix) - 0x80, nil
+	case prefix < 0xC0:
+		sizeLen = int(prefix) - 0xB7
+	case prefix < 0xF8:
+		return true, 1, int(prefix) - 0xC0, nil
+	default:
+		isList = true
+		sizeLen = int(prefix) - 0xF7
+	}
+
+	if 1+sizeLen > len(buf) {
+		return false, 0, 0, io.ErrUnexpectedEOF
+	}
+	size := bytesToInt(buf[1 : 1+sizeLen])
+	if !size.IsInt64() || size.Int64() > int64(math.MaxInt32) {
+		return false, 0, 0, rlp.ErrValueTooLarge
+	}
+	return isList, 1 + sizeLen, int(size.Int64()), nil
+}
+
+// rlpError turns an error from the rlp package into a parseError for the item at offset
+func rlpError(err error, offset int) *parseError {
+	if err == rlp.ErrValueTooLarge || err == io.ErrUnexpectedEOF {
+		return newParseError(errTruncated, offset, "The RLP item starting here is longer than the remaining input.")
+	}
+	return newParseError(errInvalidRLP, offset, "%v", err)
+}
+
+// rlpItemTokens explains a single valid RLP item. Lists become one token with
+// their prefix and items as children, strings become their prefix (if any) and value.
+// Offsets are relative to the start of enc.
+func rlpItemTokens(enc []byte) []token {
+	k, content, _, _ := rlp.Split(enc)
+	prefix, prefixLen := addRLPToken(enc)
+
+	if k == rlp.List {
+		children := []token{*prefix}
+		items, _ := splitRLPItems(content, prefixLen)
+		off := prefixLen
+		for _, item := range items {
+			children = append(children, shiftTokens(rlpItemTokens(item), off)...)
+			off += len(item)
+		}
+		return []token{{
+			Token:       hex.EncodeToString(enc),
+			Title:       "RLP List",
+			Description: fmt.Sprintf("An RLP 'list' containing %d items.", len(items)),
+			Value:       fmt.Sprintf("%d items", len(items)),
+			Length:      len(enc),
+			Children:    children,
+		}}
+	}
+
+	var toks []token
+	if prefix != nil {
+		toks = append(toks, *prefix)
+	}
+	body := enc[prefixLen:]
+	toks = append(toks, token{
+		Token:       hex.EncodeToString(body),
+		Title:       "RLP String",
+		Description: fmt.Sprintf("An RLP 'string' of %d bytes. RLP doesn't say what the bytes mean, that is up to whoever encoded them.", len(content)),
+		FlavorText:  describeRLPString(content),
+		Value:       "0x" + hex.EncodeToString(content),
+		Offset:      prefixLen,
+		Length:      len(body),
+	})
+	return toks
+}
+
+// guess at what an RLP string might hold
+func describeRLPString(b []byte) string {
+	switch {
+	case len(b) == 0:
+		return "An empty string, which is also how RLP encodes the integer 0."
+	case len(b) > 1 && isPrintable(b):
+		return fmt.Sprintf("As text this reads %q.", string(b))
+	case len(b) == 20:
+		return "20 bytes is the size of an Ethereum address."
+	case len(b) == 32:
+		return "32 bytes is the size of a hash or an EVM word."
+	case len(b) < 32 && b[0] != 0:
+		return fmt.Sprintf("As an integer this is %s.", bytesToInt(b).String())
+	}
+	return ""
+}
+
+func isPrintable(b []byte) bool {
+	for _, c := range b {
+		if c < 0x20 || c > 0x7e {
+			return false
+		}
+	}
+	return true
+}
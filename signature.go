@@ -0,0 +1,231 @@
+package main
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+var (
+	secp256k1N     = crypto.S256().Params().N
+	secp256k1HalfN = new(big.Int).Rsh(secp256k1N, 1)
+)
+
+// checkSignatureField checks the value of a signature field. It returns a note on the
+// checks that passed and a warning token for each that failed, relative to body.
+func checkSignatureField(f EthField, value *big.Int, body []byte) (string, []token) {
+	switch f {
+	case SIG_R, SIG_S:
+		name := "r"
+		if f == SIG_S {
+			name = "s"
+		}
+		if value.Sign() == 0 || value.Cmp(secp256k1N) >= 0 {
+			return "", []token{warningToken(body, fmt.Sprintf("Signature %s Out Of Range", strings.ToUpper(name)),
+				fmt.Sprintf("%s has to be between 1 and n - 1, where n is the order of the secp256k1 curve (0x%x). No private key can produce this signature, so nodes reject it.", name, secp256k1N))}
+		}
+		if f == SIG_S && value.Cmp(secp256k1HalfN) > 0 {
+			return "", []token{warningToken(body, "High S Value",
+				fmt.Sprintf("EIP-2 requires s to be in the lower half of the curve order. For every signature (r, s) there is a second valid one, (r, n - s) with the other y parity, and only allowing the low one stops anyone from changing a transaction's hash by flipping its signature.\n"+
+					"Nodes have rejected transactions with a high s since Homestead. The canonical s for this signature is 0x%x.", new(big.Int).Sub(secp256k1N, value)))}
+		}
+		if f == SIG_S {
+			return "s is in the lower half of the curve order, as EIP-2 requires.", nil
+		}
+		return "r is between 1 and the curve order, as it should be.", nil
+	case SIG_V:
+		if _, _, ok := decodeV(value); !ok {
+			return "", []token{warningToken(body, "Invalid V",
+				fmt.Sprintf("A legacy transaction's v is 27 or 28, or chainId * 2 + 35 or 36 since EIP-155, but this is %s. The sender can't be recovered from it.", value.String()))}
+		}
+	case Y_PARITY:
+		if value.Cmp(big.NewInt(1)) > 0 {
+			msg := fmt.Sprintf("Typed transactions and authorizations store the plain y parity, which can only be 0 or 1, but this is %s.", value.String())
+			if _, _, ok := decodeV(value); ok {
+				msg += " It looks like a legacy v value, which typed transactions don't use because the chain ID is a field of its own."
+			}
+			return "", []token{warningToken(body, "Invalid Y Parity", msg)}
+		}
+	}
+	return "", nil
+}
+
+// recoverSigner returns the address whose key made the signature (r, s) over hash.
+// parity is the y parity of the signature's curve point and has to be 0 or 1.
+func recoverSigner(hash, r, s []byte, parity *big.Int) (common.Address, error) {
+	if !parity.IsUint64() || parity.Uint64() > 1 {
+		return common.Address{}, fmt.Errorf("the y parity should be 0 or 1 but it is %s", parity.String())
+	}
+	if len(r) > 32 || len(s) > 32 {
+		return common.Address{}, fmt.Errorf("r and s can be at most 32 bytes")
+	}
+
+	// crypto wants the 65 byte [r || s || v] form
+	sig := make([]byte, 65)
+	copy(sig[32-len(r):32], r)
+	copy(sig[64-len(s):64], s)
+	sig[64] = byte(parity.Uint64())
+
+	pub, err := crypto.SigToPub(hash, sig)
+	if err != nil {
+		return common.Address{}, err
+	}
+	return crypto.PubkeyToAddress(*pub), nil
+}
+
+// decodeV splits the v of a legacy signature into the chain ID and the y parity.
+// Since EIP-155 v is chainId * 2 + 35 + parity, before that it was 27 + parity
+// and chainID is nil. ok is false if v is neither.
+func decodeV(v *big.Int) (chainID *big.Int, parity uint64, ok bool) {
+	switch {
+	case v.Cmp(big.NewInt(27)) == 0 || v.Cmp(big.NewInt(28)) == 0:
+		return nil, v.Uint64() - 27, true
+	case v.Cmp(big.NewInt(35)) >= 0:
+		n := new(big.Int).Sub(v, big.NewInt(35))
+		mod := new(big.Int)
+		chainID, mod = new(big.Int).DivMod(n, big.NewInt(2), mod)
+		return chainID, mod.Uint64(), true
+	}
+	return nil, 0, false
+}
+
+// signingPayload is what the sender of a fully decoded transaction signed: its fields
+// without the signature, with the chain ID and two zeros appended for EIP-155 legacy
+// transactions and the type byte in front for typed ones. scheme describes which it is.
+func signingPayload(layout txLayout, fields txFields) (payload []byte, scheme string, err error) {
+	var items []rlp.RawValue
+	for _, f := range layout.fields {
+		if !f.isSignature() {
+			items = append(items, fields[f])
+		}
+	}
+
+	if layout.typ != 0 {
+		enc, err := rlp.EncodeToBytes(items)
+		if err != nil {
+			return nil, "", err
+		}
+		scheme = fmt.Sprintf("keccak256(0x%02x || rlp([the fields before the signature]))", layout.typ)
+		return append([]byte{layout.typ}, enc...), scheme, nil
+	}
+
+	chainID, _, ok := decodeV(fields.int(SIG_V))
+	if !ok {
+		return nil, "", fmt.Errorf("v is %s, which is neither 27 or 28 nor chainId * 2 + 35 or 36", fields.int(SIG_V).String())
+	}
+	scheme = "keccak256(rlp([nonce, gasPrice, gasLimit, to, value, data])), the original scheme from before chain IDs"
+	if chainID != nil {
+		id, err := rlp.EncodeToBytes(chainID)
+		if err != nil {
+			return nil, "", err
+		}
+		zero := rlp.RawValue{0x80}
+		items = append(items, id, zero, zero)
+		scheme = fmt.Sprintf("keccak256(rlp([nonce, gasPrice, gasLimit, to, value, data, %s, 0, 0])), the EIP-155 scheme that mixes in the chain ID", chainID.String())
+	}
+	enc, err := rlp.EncodeToBytes(items)
+	return enc, scheme, err
+}
+
+// txSender recovers who signed a fully decoded transaction
+func txSender(layout txLayout, fields txFields) (common.Address, []byte, string, error) {
+	payload, scheme, err := signingPayload(layout, fields)
+	if err != nil {
+		return common.Address{}, nil, "", err
+	}
+	hash := crypto.Keccak256(payload)
+
+	parity := fields.int(Y_PARITY)
+	if layout.typ == 0 {
+		_, p, _ := decodeV(fields.int(SIG_V))
+		parity = new(big.Int).SetUint64(p)
+	}
+	from, err := recoverSigner(hash, fields.content(SIG_R), fields.content(SIG_S), parity)
+	return from, hash, scheme, err
+}
+
+// senderTokens explains who sent a fully decoded transaction
+func senderTokens(layout txLayout, fields txFields) []token {
+	if _, ok := fields[SIG_S]; !ok {
+		return nil
+	}
+
+	tok := token{
+		Title: "Sender",
+		Description: "Transactions don't say who sent them. The sender is recovered from the signature instead, with ecrecover: " +
+			"given the hash that was signed and the (r, s) signature with its y parity, there is exactly one public key that could have made it, and the address is the last 20 bytes of the keccak256 hash of that key.\n" +
+			"Storing the address as well would cost 20 more bytes in every transaction and could never be trusted without checking the signature anyway.",
+		Kind: kindDerived,
+	}
+	from, hash, _, err := txSender(layout, fields)
+	if err != nil {
+		tok.Value = "Can't recover the sender"
+		tok.FlavorText = fmt.Sprintf("The signature isn't valid: %v. Nodes would reject this transaction.", err)
+		return []token{tok}
+	}
+	tok.Value = from.Hex()
+	tok.FlavorText = fmt.Sprintf("Recovered from the signature over the signing hash 0x%x.", hash)
+	return []token{tok}
+}
+
+// txEncoding is the canonical encoding of a fully decoded transaction, the bytes
+// that go into a block. For a blob transaction in its network form that leaves out the blobs.
+func txEncoding(layout txLayout, fields txFields) ([]byte, error) {
+	items := make([]rlp.RawValue, len(layout.fields))
+	for i, f := range layout.fields {
+		items[i] = fields[f]
+	}
+	enc, err := rlp.EncodeToBytes(items)
+	if err != nil || layout.typ == 0 {
+		return enc, err
+	}
+	return append([]byte{layout.typ}, enc...), nil
+}
+
+// hashTokens explains the two hashes of a fully decoded transaction: the one that
+// identifies it and the one its sender signed
+func hashTokens(layout txLayout, fields txFields) []token {
+	if _, ok := fields[SIG_S]; !ok {
+		return nil
+	}
+
+	var toks []token
+	if enc, err := txEncoding(layout, fields); err == nil {
+		how := "keccak256 of the whole RLP encoded transaction, signature included."
+		if layout.typ != 0 {
+			how = fmt.Sprintf("keccak256 of the type byte 0x%02x followed by the RLP encoded fields, signature included.", layout.typ)
+		}
+		toks = append(toks, token{
+			Title:       "Transaction Hash",
+			Description: "The hash that identifies this transaction, the one block explorers and wallets show. It is the " + how,
+			FlavorText:  "Because it covers the signature it can only be known once the transaction is signed, and it is never stored in the transaction itself.",
+			Value:       fmt.Sprintf("0x%x", crypto.Keccak256(enc)),
+			Kind:        kindDerived,
+		})
+	}
+
+	payload, scheme, err := signingPayload(layout, fields)
+	if err != nil {
+		return toks
+	}
+	differs := "It differs from the transaction hash because it leaves out the signature, which can't sign itself."
+	if layout.typ != 0 {
+		differs += fmt.Sprintf("\nThe type byte 0x%02x is signed too, so the signature can't be reused for a different kind of transaction with the same fields.", layout.typ)
+	} else if chainID, _, _ := decodeV(fields.int(SIG_V)); chainID != nil {
+		differs += fmt.Sprintf("\nThe chain ID %s and two zeros stand in where v, r and s go, so the signature is only valid on that chain even though the chain ID isn't a field of the transaction.", chainID.String())
+	} else {
+		differs += "\nNothing ties it to a chain, so the same signed transaction is valid on every chain that accepts pre-EIP-155 transactions."
+	}
+	toks = append(toks, token{
+		Title:       "Signing Hash",
+		Description: "The hash the sender actually signed: " + scheme + ".",
+		FlavorText:  differs,
+		Value:       fmt.Sprintf("0x%x", crypto.Keccak256(payload)),
+		Kind:        kindDerived,
+	})
+	return toks
+}
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"encoding/hex"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// A small offline signature database in the style of 4byte.directory. Selectors
+// are only 4 bytes so unrelated signatures can share one, which is why each
+// selector maps to every signature we know for it.
+
+// functionSignatures maps hex selectors to the signatures that hash to them
+var functionSignatures = map[string][]string{}
+
+// signatures of commonly called functions
+var knownFunctions = []string{
+	// ERC-20
+	"transfer(address,uint256)",
+	"transferFrom(address,address,uint256)",
+	"approve(address,uint256)",
+	"balanceOf(address)",
+	"allowance(address,address)",
+	"totalSupply()",
+	"decimals()",
+	"symbol()",
+	"name()",
+	"increaseAllowance(address,uint256)",
+	"decreaseAllowance(address,uint256)",
+	"permit(address,address,uint256,uint256,uint8,bytes32,bytes32)",
+	"mint(address,uint256)",
+	"burn(uint256)",
+	"burnFrom(address,uint256)",
+
+	// WETH
+	"deposit()",
+	"withdraw(uint256)",
+
+	// ERC-721 and ERC-1155
+	"safeTransferFrom(address,address,uint256)",
+	"safeTransferFrom(address,address,uint256,bytes)",
+	"safeTransferFrom(address,address,uint256,uint256,bytes)",
+	"safeBatchTransferFrom(address,address,uint256[],uint256[],bytes)",
+	"setApprovalForAll(address,bool)",
+	"isApprovedForAll(address,address)",
+	"ownerOf(uint256)",
+	"getApproved(uint256)",
+	"tokenURI(uint256)",
+	"balanceOfBatch(address[],uint256[])",
+
+	// ownership and proxies
+	"owner()",
+	"transferOwnership(address)",
+	"renounceOwnership()",
+	"upgradeTo(address)",
+	"upgradeToAndCall(address,bytes)",
+
+	// Uniswap
+	"swapExactTokensForTokens(uint256,uint256,address[],address,uint256)",
+	"swapTokensForExactTokens(uint256,uint256,address[],address,uint256)",
+	"swapExactETHForTokens(uint256,address[],address,uint256)",
+	"swapExactTokensForETH(uint256,uint256,address[],address,uint256)",
+	"addLiquidity(address,address,uint256,uint256,uint256,uint256,address,uint256)",
+	"addLiquidityETH(address,uint256,uint256,uint256,address,uint256)",
+	"removeLiquidity(address,address,uint256,uint256,uint256,address,uint256)",
+	"exactInputSingle((address,address,uint24,address,uint256,uint256,uint256,uint160))",
+	"exactInput((bytes,address,uint256,uint256,uint256))",
+	"multicall(bytes[])",
+	"multicall(uint256,bytes[])",
+	"execute(bytes,bytes[],uint256)",
+	"execute(bytes,bytes[])",
+
+	// Multicall3
+	"aggregate((address,bytes)[])",
+	"aggregate3((address,bool,bytes)[])",
+	"tryAggregate(bool,(address,bytes)[])",
+
+	// Safe
+	"execTransaction(address,uint256,bytes,uint8,uint256,uint256,uint256,address,address,bytes)",
+
+	// staking and misc
+	"stake(uint256)",
+	"claim()",
+	"withdraw()",
+	"deposit(uint256)",
+	"setName(string)",
+
+	// shares a selector with burn(uint256)
+	"collate_propagate_storage(bytes16)",
+}
+
+// errorSignatures maps hex selectors to the custom errors that hash to them
+var errorSignatures = map[string][]string{}
+
+// custom errors of commonly used contracts. Error(string) and Panic(uint256) are built
+// into solidity and handled on their own.
+var knownErrors = []string{
+	// OpenZeppelin 5
+	"ERC20InsufficientBalance(address,uint256,uint256)",
+	"ERC20InsufficientAllowance(address,uint256,uint256)",
+	"ERC20InvalidSender(address)",
+	"ERC20InvalidReceiver(address)",
+	"ERC20InvalidApprover(address)",
+	"ERC20InvalidSpender(address)",
+	"ERC721NonexistentToken(uint256)",
+	"ERC721IncorrectOwner(address,uint256,address)",
+	"ERC721InsufficientApproval(address,uint256)",
+	"ERC721InvalidReceiver(address)",
+	"ERC1155InsufficientBalance(address,uint256,uint256,uint256)",
+	"OwnableUnauthorizedAccount(address)",
+	"OwnableInvalidOwner(address)",
+	"AccessControlUnauthorizedAccount(address,bytes32)",
+	"ReentrancyGuardReentrantCall()",
+	"EnforcedPause()",
+	"ExpectedPause()",
+	"SafeERC20FailedOperation(address)",
+	"AddressEmptyCode(address)",
+	"FailedInnerCall()",
+	"InvalidInitialization()",
+	"NotInitializing()",
+	"ECDSAInvalidSignature()",
+
+	// Permit2
+	"AllowanceExpired(uint256)",
+	"InsufficientAllowance(uint256)",
+	"InvalidNonce()",
+	"InvalidSignature()",
+	"SignatureExpired(uint256)",
+
+	// Uniswap
+	"V3TooLittleReceived()",
+	"V3TooMuchRequested()",
+	"V2TooLittleReceived()",
+	"V2TooMuchRequested()",
+	"TransactionDeadlinePassed()",
+	"ExecutionFailed(uint256,bytes)",
+
+	// common hand written ones
+	"Unauthorized()",
+	"InsufficientBalance()",
+	"TransferFailed()",
+	"ZeroAddress()",
+}
+
+// eventSignatures maps hex topic hashes to the events that hash to them. Which arguments
+// are indexed isn't part of the hash, so the same event can be listed more than once.
+var eventSignatures = map[string][]string{}
+
+// events of commonly used contracts, written like in solidity so they keep their
+// argument names and which ones are indexed
+var knownEvents = []string{
+	// ERC-20 and ERC-721, which only differ in what is indexed
+	"Transfer(address indexed from, address indexed to, uint256 value)",
+	"Transfer(address indexed from, address indexed to, uint256 indexed tokenId)",
+	"Approval(address indexed owner, address indexed spender, uint256 value)",
+	"Approval(address indexed owner, address indexed approved, uint256 indexed tokenId)",
+	"ApprovalForAll(address indexed owner, address indexed operator, bool approved)",
+
+	// ERC-1155
+	"TransferSingle(address indexed operator, address indexed from, address indexed to, uint256 id, uint256 value)",
+	"TransferBatch(address indexed operator, address indexed from, address indexed to, uint256[] ids, uint256[] values)",
+	"URI(string value, uint256 indexed id)",
+
+	// WETH
+	"Deposit(address indexed dst, uint256 wad)",
+	"Withdrawal(address indexed src, uint256 wad)",
+
+	// Uniswap
+	"Swap(address indexed sender, uint256 amount0In, uint256 amount1In, uint256 amount0Out, uint256 amount1Out, address indexed to)",
+	"Swap(address indexed sender, address indexed recipient, int256 amount0, int256 amount1, uint160 sqrtPriceX96, uint128 liquidity, int24 tick)",
+	"Sync(uint112 reserve0, uint112 reserve1)",
+	"Mint(address indexed sender, uint256 amount0, uint256 amount1)",
+	"Burn(address indexed sender, uint256 amount0, uint256 amount1, address indexed to)",
+	"PairCreated(address indexed token0, address indexed token1, address pair, uint256 index)",
+
+	// ownership, access control and proxies
+	"OwnershipTransferred(address indexed previousOwner, address indexed newOwner)",
+	"RoleGranted(bytes32 indexed role, address indexed account, address indexed sender)",
+	"RoleRevoked(bytes32 indexed role, address indexed account, address indexed sender)",
+	"Upgraded(address indexed implementation)",
+	"AdminChanged(address previousAdmin, address newAdmin)",
+	"Initialized(uint64 version)",
+	"Paused(address account)",
+	"Unpaused(address account)",
+}
+
+func init() {
+	for _, sig := range knownFunctions {
+		sel := selector(sig)
+		functionSignatures[sel] = append(functionSignatures[sel], sig)
+	}
+	for _, sig := range knownErrors {
+		sel := selector(sig)
+		errorSignatures[sel] = append(errorSignatures[sel], sig)
+	}
+	for _, decl := range knownEvents {
+		e, err := parseEventDeclaration(decl)
+		if err != nil {
+			panic(fmt.Sprintf("bad event %q: %v", decl, err))
+		}
+		topic := hex.EncodeToString(crypto.Keccak256([]byte(e.sig)))
+		eventSignatures[topic] = append(eventSignatures[topic], decl)
+	}
+}
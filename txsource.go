@@ -0,0 +1,130 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// TxSource looks up the raw signed bytes of a transaction by its hash
+type TxSource interface {
+	RawTransaction(hash string) ([]byte, error)
+}
+
+// txSource is where ethTxParser resolves transaction hashes. nil means hashes
+// aren't looked up at all. See configureTxSource.
+var txSource TxSource
+
+// configureTxSource picks a TxSource from the environment. TX_FIXTURES names a
+// fixture file and wins over ETH_RPC_URL, the JSON-RPC endpoint of a node.
+func configureTxSource() error {
+	if path := os.Getenv("TX_FIXTURES"); path != "" {
+		src, err := newFileTxSource(path)
+		if err != nil {
+			return err
+		}
+		txSource = src
+		return nil
+	}
+	if url := os.Getenv("ETH_RPC_URL"); url != "" {
+		txSource = newRPCTxSource(url)
+	}
+	return nil
+}
+
+// rpcTxSource fetches transactions from an Ethereum node with eth_getRawTransactionByHash
+type rpcTxSource struct {
+	endpoint string
+	client   *http.Client
+}
+
+func newRPCTxSource(endpoint string) *rpcTxSource {
+	return &rpcTxSource{
+		endpoint: endpoint,
+		client:   &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+type rpcRequest struct {
+	JSONRPC string        `json:"jsonrpc"`
+	ID      int           `json:"id"`
+	Method  string        `json:"method"`
+	Params  []interface{} `json:"params"`
+}
+
+type rpcResponse struct {
+	Result *string `json:"result"`
+	Error  *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+func (r *rpcTxSource) RawTransaction(hash string) ([]byte, error) {
+	body, err := json.Marshal(rpcRequest{
+		JSONRPC: "2.0",
+		ID:      1,
+		Method:  "eth_getRawTransactionByHash",
+		Params:  []interface{}{hash},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := r.client.Post(r.endpoint, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return nil, newParseError(errFetchFailed, -1, "could not reach the transaction source: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, newParseError(errFetchFailed, -1, "the transaction source answered with %s", resp.Status)
+	}
+
+	var res rpcResponse
+	if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		return nil, newParseError(errFetchFailed, -1, "the transaction source sent an invalid response: %v", err)
+	}
+	if res.Error != nil {
+		return nil, newParseError(errFetchFailed, -1, "the transaction source returned an error: %s (%d)", res.Error.Message, res.Error.Code)
+	}
+	if res.Result == nil || *res.Result == "0x" {
+		return nil, newParseError(errTxNotFound, -1, "no transaction found with hash %s", hash)
+	}
+	return decodeHex(*res.Result)
+}
+
+// fileTxSource serves transactions from a JSON file that maps hashes to raw
+// transaction hex, for working offline and in tests.
+type fileTxSource struct {
+	txs map[string]string
+}
+
+func newFileTxSource(path string) (*fileTxSource, error) {
+	buf, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var txs map[string]string
+	if err := json.Unmarshal(buf, &txs); err != nil {
+		return nil, fmt.Errorf("reading tx fixtures from %s: %v", path, err)
+	}
+
+	src := &fileTxSource{txs: make(map[string]string, len(txs))}
+	for hash, raw := range txs {
+		src.txs[strings.ToLower(hash)] = raw
+	}
+	return src, nil
+}
+
+func (f *fileTxSource) RawTransaction(hash string) ([]byte, error) {
+	raw, ok := f.txs[strings.ToLower(hash)]
+	if !ok {
+		return nil, newParseError(errTxNotFound, -1, "no transaction found with hash %s", hash)
+	}
+	return decodeHex(raw)
+}
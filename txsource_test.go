@@ -0,0 +1,81 @@
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// a signed legacy transaction that creates an empty contract
+const fixtureTx = "f85001843b9aca008307a12080808025a0b0e4608e82ba2f30390396c52d3c2bb8c2e84f7a11b66f10b2fcb2abc3715627a0210c77635a54874ebd8c6db7e1f133704a0d3edc1efe8db4a0c763de55a18419"
+
+func TestHashLookupFromFixtures(t *testing.T) {
+	raw, _ := hex.DecodeString(fixtureTx)
+	hash := "0x" + hex.EncodeToString(crypto.Keccak256(raw))
+
+	dir, err := ioutil.TempDir("", "txfixtures")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "txs.json")
+	buf, _ := json.Marshal(map[string]string{hash: "0x" + fixtureTx})
+	if err := ioutil.WriteFile(path, buf, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	os.Setenv("TX_FIXTURES", path)
+	defer os.Unsetenv("TX_FIXTURES")
+	if err := configureTxSource(); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { txSource = nil }()
+
+	resp, perr := explain(request{Input: hash})
+	if perr != nil {
+		t.Fatal(perr)
+	}
+	var interp *interpretation
+	for i := range resp.Results {
+		if resp.Results[i].Parser == "eth-tx" {
+			interp = &resp.Results[i]
+		}
+	}
+	if interp == nil {
+		t.Fatalf("the hash wasn't explained as a transaction, got %+v", resp.Results)
+	}
+	if interp.Error != nil {
+		t.Fatal(interp.Error)
+	}
+	if interp.Tokens[0].Title != "Legacy Transaction" || interp.Tokens[0].Length != len(raw) {
+		t.Errorf("got a %d byte %q, want the %d byte legacy transaction from the fixtures", interp.Tokens[0].Length, interp.Tokens[0].Title, len(raw))
+	}
+	if tok := findToken(interp.Tokens, "Transaction Hash"); tok == nil || tok.Value != hash {
+		t.Errorf("got transaction hash %+v, want %s", tok, hash)
+	}
+
+	// hashes missing from the fixtures are reported, not guessed at
+	other := "0x" + hex.EncodeToString(crypto.Keccak256([]byte("missing")))
+	resp, perr = explain(request{Input: other, Hint: "eth-tx"})
+	if perr == nil || perr.Code != errTxNotFound {
+		t.Errorf("got %v, want a %s error", perr, errTxNotFound)
+	}
+}
+
+// findToken is the first token titled title in toks or their children
+func findToken(toks []token, title string) *token {
+	for i := range toks {
+		if toks[i].Title == title {
+			return &toks[i]
+		}
+		if tok := findToken(toks[i].Children, title); tok != nil {
+			return tok
+		}
+	}
+	return nil
+}
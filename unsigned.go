@@ -0,0 +1,104 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// Wallets and hardware signers are handed a transaction before it has a signature.
+// These layouts explain what they are given, which is also exactly what they hash and sign.
+
+var unsignedLegacyLayout = txLayout{
+	name:     "Unsigned Legacy Transaction",
+	desc:     "A legacy transaction that hasn't been signed yet, an RLP 'list' of the first 6 fields.\nThis is also the payload a signer hashes and signs for a pre-EIP-155 signature, which isn't tied to any chain.",
+	fields:   []EthField{NONCE, GAS_PRICE, GAS_LIMIT, RECIPIENT, VALUE, DATA},
+	unsigned: true,
+}
+
+var eip155SigningLayout = txLayout{
+	name:     "EIP-155 Signing Payload",
+	desc:     "What a signer hashes and signs for a legacy transaction since EIP-155: the 6 transaction fields followed by the chain ID and two zeros.\nThe zeros sit where r and s will go. Once signed, the chain ID and the zeros are replaced by the real v, r and s.",
+	fields:   []EthField{NONCE, GAS_PRICE, GAS_LIMIT, RECIPIENT, VALUE, DATA, CHAIN_ID, EIP155_ZERO, EIP155_ZERO},
+	unsigned: true,
+}
+
+// unsignedLayout is the layout of a typed transaction before it is signed, the same fields without the signature
+func (l txLayout) unsignedLayout() txLayout {
+	u := txLayout{
+		name:     "Unsigned " + l.name,
+		desc:     l.desc + "\nThis one hasn't been signed yet. The type byte and these fields are exactly what a signer hashes and signs.",
+		typ:      l.typ,
+		unsigned: true,
+	}
+	for _, f := range l.fields {
+		if !f.isSignature() {
+			u.fields = append(u.fields, f)
+		}
+	}
+	return u
+}
+
+// txItems splits a well formed RLP list into its encoded items. ok is false if buf isn't one.
+func txItems(buf []byte) ([][]byte, bool) {
+	if validRLP(buf, 0) != nil {
+		return nil, false
+	}
+	k, content, _, _ := rlp.Split(buf)
+	if k != rlp.List {
+		return nil, false
+	}
+	items, err := splitRLPItems(content, 0)
+	return items, err == nil
+}
+
+// legacyLayoutFor picks the layout of a legacy transaction list by counting its fields.
+// Anything it doesn't recognize is explained as a signed transaction.
+func legacyLayoutFor(buf []byte) txLayout {
+	items, ok := txItems(buf)
+	switch {
+	case !ok:
+	case len(items) == len(unsignedLegacyLayout.fields):
+		return unsignedLegacyLayout
+	// no signature has an r or s of zero
+	case len(items) == len(eip155SigningLayout.fields) && items[7][0] == 0x80 && items[8][0] == 0x80:
+		return eip155SigningLayout
+	}
+	return legacyLayout
+}
+
+// typedLayoutFor picks between a typed transaction's signed and unsigned layouts
+func typedLayoutFor(layout txLayout, payload []byte) txLayout {
+	unsigned := layout.unsignedLayout()
+	if items, ok := txItems(payload); ok && len(items) == len(unsigned.fields) {
+		return unsigned
+	}
+	return layout
+}
+
+// unsignedTokens explains what signing a fully decoded unsigned transaction would
+// hash and add. buf is the whole input, which is exactly what gets hashed.
+func unsignedTokens(layout txLayout, buf []byte) []token {
+	added := "A signer appends the y parity, r and s to the list, leaving the type byte in front. The result is the signed transaction."
+	if layout.typ == 0 {
+		added = "A signer appends v, r and s to make the 9 fields of a signed transaction. Here v is 27 or 28, so the signature works on every chain."
+		if layout.fields[len(layout.fields)-1] == EIP155_ZERO {
+			added = "A signer replaces the chain ID and the two zeros with v, r and s. v becomes chainId * 2 + 35 or 36, depending on the y parity, so the chain ID can still be worked out from it."
+		}
+	}
+
+	return []token{{
+		Title:       "Signing Hash",
+		Description: "The keccak256 hash of exactly these bytes. This is what a wallet or hardware signer signs with the sender's private key.",
+		FlavorText:  "Signing a hash rather than the transaction itself keeps the signature the same size however big the transaction is.",
+		Value:       fmt.Sprintf("0x%x", crypto.Keccak256(buf)),
+		Kind:        kindDerived,
+	}, {
+		Title:       "Missing Signature",
+		Description: "This transaction isn't signed, so nodes would reject it and there is no sender or transaction hash yet. Both come from the signature.",
+		FlavorText:  added,
+		Value:       "Not signed yet",
+		Kind:        kindDerived,
+	}}
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// wordParser explains a bare 32 byte value. On its own a word like this is
+// ambiguous so this parser mostly exists to list what else it could be.
+type wordParser struct{}
+
+func init() {
+	registerParser(parserInfo{
+		Name:        "word",
+		Type:        "32 Byte Word",
+		Priority:    50,
+		Description: "A lone 32 byte value such as a hash, a private key or a storage slot.",
+	}, &wordParser{})
+}
+
+func (wp *wordParser) understands(s string) bool {
+	buf, err := hex.DecodeString(strings.TrimPrefix(s, "0x"))
+	return err == nil && len(buf) == 32
+}
+
+func (wp *wordParser) confidence(s string) float64 {
+	return 0.3
+}
+
+func (wp *wordParser) parse(s string, opts options) ([]token, error) {
+	buf, err := decodeHex(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(buf) != 32 {
+		return nil, newParseError(errWrongLength, -1, "expected 32 bytes but got %d", len(buf))
+	}
+
+	desc := "32 bytes is the native word size of the EVM, so a value like this could be many things.\n" +
+		"It could be a transaction or block hash, a storage slot key, a private key or simply a uint256."
+
+	flavor := fmt.Sprintf("As a uint256 this is %s.", bytesToInt(buf).String())
+	if key, err := crypto.ToECDSA(buf); err == nil {
+		flavor += fmt.Sprintf("\nAs a private key it controls the address %s.", crypto.PubkeyToAddress(key.PublicKey).Hex())
+	}
+	flavor += fmt.Sprintf("\nAs a storage slot it is slot %s, unless it was derived with keccak256 for a mapping or dynamic array.", bytesToInt(buf).String())
+
+	return []token{{
+		Token:       hex.EncodeToString(buf),
+		Title:       "32 Byte Word",
+		Description: desc,
+		FlavorText:  flavor,
+		Value:       "0x" + hex.EncodeToString(buf),
+		Length:      len(buf),
+	}}, nil
+}
@@ -1,25 +1,54 @@
 package main
 
 import (
+	"bytes"
+	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
+	"strings"
 
 	"github.com/btcsuite/btcutil/base58"
 )
 
 type xpubParser struct{}
 
+// the human readable prefixes the version bytes give common extended keys
+var extendedKeyPrefixes = []string{"xpub", "xprv", "ypub", "yprv", "zpub", "zprv", "Ypub", "Zpub", "tpub", "tprv", "Ltub", "Ltpv", "Mtub", "Mtpv"}
+
+func init() {
+	registerParser(parserInfo{
+		Name:        "xpub",
+		Type:        "XPUB (Base58 decoded)",
+		Priority:    200,
+		Description: "BIP32 extended keys (xpub, ypub, zpub, ...).",
+	}, &xpubParser{})
+}
+
 func (x *xpubParser) understands(buf string) bool {
-	if _, err := tokenizeXPUB(buf); err != nil {
-		return false
+	if len(decodeXPUB(buf)) == 82 {
+		return true
+	}
+	// recognisably an extended key even if it's damaged
+	for _, prefix := range extendedKeyPrefixes {
+		if strings.HasPrefix(buf, prefix) && len(buf) > 100 {
+			return true
+		}
 	}
-	return true
+	return false
 }
 
-func (x *xpubParser) parse(buf string) ([]token, error) {
+func (x *xpubParser) confidence(buf string) float64 {
+	// a matching checksum leaves little doubt
+	if validXPUBChecksum(decodeXPUB(buf)) {
+		return 0.99
+	}
+	return 0.5
+}
+
+func (x *xpubParser) parse(buf string, opts options) ([]token, error) {
 	toks, err := tokenizeXPUB(buf)
 	if err != nil {
-		return nil, err
+		return toks, err
 	}
 	return toks, nil
 }
@@ -28,23 +57,39 @@ func decodeXPUB(xpub string) []byte {
 	return base58.Decode(xpub)
 }
 
+// the checksum is the first 4 bytes of sha256(sha256(payload))
+func validXPUBChecksum(xpub []byte) bool {
+	if len(xpub) != 82 {
+		return false
+	}
+	first := sha256.Sum256(xpub[:78])
+	second := sha256.Sum256(first[:])
+	return bytes.Equal(second[:4], xpub[78:82])
+}
+
 func tokenizeXPUB(encoded string) ([]token, error) {
 
 	// decode from base58
 	xpub := decodeXPUB(string(encoded))
 
-	if len(xpub) < 82 {
-		return nil, fmt.Errorf("%s is not a valid xpub", encoded)
+	if len(xpub) == 0 {
+		return nil, newParseError(errInvalidXPUB, 0, "%s is not valid base58", encoded)
 	}
-
 	// TODO: Probably add a lot of 0x prefixes on value?
 
+	// build the tokens from a full size copy so a short key can still be explained up to where it ends
+	full := make([]byte, 82)
+	copy(full, xpub)
+	xpub, decoded := full, xpub
+
 	version := token{
 		Token:       hex.EncodeToString(xpub[0:4]),
 		Title:       "Version",
 		Description: "The version gives information into what kind of key is encoded.\nThis is also what gives an XPUB its distinct form (XPUB, LTUB, ZPUB).",
 		FlavorText:  "This is also what gives an XPUB its distinct form (XPUB, LTUB, ZPUB).",
 		Value:       bytesToInt(xpub[0:4]).String(),
+		Offset:      0,
+		Length:      4,
 	}
 	depth := token{
 		Token:       hex.EncodeToString(xpub[4:5]),
@@ -52,6 +97,8 @@ func tokenizeXPUB(encoded string) ([]token, error) {
 		Description: "The Depth byte tells you have what generation key this is.\nIn other words it tells you how many parent keys or ancestors lead up to this key.",
 		FlavorText:  "In other words it tells you how many parent keys or ancestors lead up to this key.",
 		Value:       fmt.Sprintf("%s (0x%x)", bytesToInt(xpub[4:5]).String(), xpub[4:5]),
+		Offset:      4,
+		Length:      1,
 	}
 	fingerprint := token{
 		Token:       hex.EncodeToString(xpub[5:9]),
@@ -59,6 +106,8 @@ func tokenizeXPUB(encoded string) ([]token, error) {
 		Description: "The Fingerprint is used to verify the parent key.",
 		FlavorText:  "",
 		Value:       "0x" + hex.EncodeToString(xpub[5:9]),
+		Offset:      5,
+		Length:      4,
 	}
 	index := token{
 		Token:       hex.EncodeToString(xpub[9:13]),
@@ -66,6 +115,8 @@ func tokenizeXPUB(encoded string) ([]token, error) {
 		Description: "The Index tells you what child of the parent key this is.\nEach parent can support up to 2^32 child keys.",
 		FlavorText:  "Each parent can support up to 2^32 child keys.",
 		Value:       fmt.Sprintf("%s (0x%x)", bytesToInt(xpub[9:13]).String(), xpub[9:13]),
+		Offset:      9,
+		Length:      4,
 	}
 	chaincode := token{
 		Token:       hex.EncodeToString(xpub[13:45]),
@@ -73,6 +124,8 @@ func tokenizeXPUB(encoded string) ([]token, error) {
 		Description: "The Chaincode is used to deterministically derive child keys of this key.",
 		FlavorText:  "",
 		Value:       "0x" + hex.EncodeToString(xpub[13:45]),
+		Offset:      13,
+		Length:      32,
 	}
 	keydata := token{
 		Token:       hex.EncodeToString(xpub[45:78]),
@@ -80,6 +133,8 @@ func tokenizeXPUB(encoded string) ([]token, error) {
 		Description: "The Keydata is the actual bytes of this extended key.\nIf the first byte is 0x00 you know that this is a public child key. Otherwise, this is a private child.",
 		FlavorText:  "If the first byte is 0x00 you know that this is a public child key. Otherwise, this is a private child.",
 		Value:       "0x" + hex.EncodeToString(xpub[45:78]),
+		Offset:      45,
+		Length:      33,
 	}
 	checksum := token{
 		Token:       hex.EncodeToString(xpub[78:82]),
@@ -87,7 +142,35 @@ func tokenizeXPUB(encoded string) ([]token, error) {
 		Description: "The Checksum is used to verify that the other data was encoded and transmitted properly.",
 		FlavorText:  "",
 		Value:       "0x" + hex.EncodeToString(xpub[78:82]),
+		Offset:      78,
+		Length:      4,
 	}
 
-	return []token{version, depth, fingerprint, index, chaincode, keydata, checksum}, nil
+	toks := []token{version, depth, fingerprint, index, chaincode, keydata, checksum}
+
+	if len(decoded) < 82 {
+		var complete []token
+		for _, tok := range toks {
+			if tok.Offset+tok.Length > len(decoded) {
+				perr := newParseError(errTruncated, tok.Offset, "The input ends in the middle of the %s. An xpub is 82 bytes but this is only %d.", tok.Title, len(decoded))
+				return append(complete, errorToken(decoded[tok.Offset:], perr)), perr
+			}
+			complete = append(complete, tok)
+		}
+	}
+	if len(decoded) > 82 {
+		perr := newParseError(errInvalidXPUB, 82, "Unexpected extra bytes. An xpub is 82 bytes but this is %d.", len(decoded))
+		return append(toks, errorToken(decoded[82:], perr)), perr
+	}
+	if !validXPUBChecksum(decoded) {
+		sum := sha256.Sum256(decoded[:78])
+		sum = sha256.Sum256(sum[:])
+		perr := newParseError(errBadChecksum, 78, "The checksum doesn't match, it should be 0x%x. At least one character of the key was probably mistyped.", sum[:4])
+		// the checksum itself is the error, so it isn't covered twice
+		checksum.Kind = kindError
+		checksum.Description = perr.Message
+		toks[len(toks)-1] = checksum
+		return toks, perr
+	}
+	return toks, nil
 }